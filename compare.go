@@ -0,0 +1,135 @@
+package etsiparser
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Filter values arrive as strings and are converted to the type of the
+// attribute they are compared with: numbers are compared numerically,
+// json.Number and integers with arbitrary precision, booleans only for
+// equality and RFC 3339 timestamps chronologically. Other strings are
+// compared lexicographically.
+
+// stringValue returns the attribute as a string if it is one. A json.Number
+// is a number, not a string.
+func stringValue(attribute interface{}) (string, bool) {
+	if _, ok := attribute.(json.Number); ok {
+		return "", false
+	}
+	v := reflect.ValueOf(attribute)
+	if v.Kind() != reflect.String {
+		return "", false
+	}
+	return v.String(), true
+}
+
+func isJSONNumber(s string) bool {
+	return s != "" && (s[0] == '-' || s[0] >= '0' && s[0] <= '9') && json.Valid([]byte(s))
+}
+
+func compareFloat(attribute float64, value string, bitSize int) (int, bool) {
+	if !isJSONNumber(value) {
+		return 0, false
+	}
+	number, err := strconv.ParseFloat(value, bitSize)
+	if err != nil {
+		return 0, false
+	}
+	switch {
+	case attribute < number:
+		return -1, true
+	case attribute > number:
+		return 1, true
+	}
+	return 0, true
+}
+
+// parseNumber parses a JSON number rounded to prec bits.
+func parseNumber(s string, prec uint) (*big.Float, bool) {
+	if !isJSONNumber(s) {
+		return nil, false
+	}
+	number, _, err := big.ParseFloat(s, 10, prec, big.ToNearestEven)
+	return number, err == nil
+}
+
+// compareNumber compares two decimal numbers. The precision suffices to
+// tell apart any two numbers with as many digits as attribute and value.
+func compareNumber(attribute string, value string) (int, bool) {
+	prec := uint(len(attribute)+len(value))*4 + 64
+	a, ok := parseNumber(attribute, prec)
+	if !ok {
+		return 0, false
+	}
+	number, ok := parseNumber(value, prec)
+	if !ok {
+		return 0, false
+	}
+	return a.Cmp(number), true
+}
+
+func compareTime(attribute time.Time, value string) (int, bool) {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return 0, false
+	}
+	switch {
+	case attribute.Before(t):
+		return -1, true
+	case attribute.After(t):
+		return 1, true
+	}
+	return 0, true
+}
+
+// isBoolValue reports whether the attribute is a boolean, which only "eq"
+// and "neq" apply to.
+func isBoolValue(attribute interface{}) bool {
+	return reflect.Indirect(reflect.ValueOf(attribute)).Kind() == reflect.Bool
+}
+
+func compareBool(attribute bool, value string) (int, bool) {
+	if strconv.FormatBool(attribute) == value {
+		return 0, true
+	}
+	return 0, false
+}
+
+func compareValue(attribute interface{}, value string) (int, bool) {
+	switch a := attribute.(type) {
+	case json.Number:
+		return compareNumber(string(a), value)
+	case time.Time:
+		return compareTime(a, value)
+	}
+	v := reflect.ValueOf(attribute)
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			return compareValue(v.Elem().Interface(), value)
+		}
+	case reflect.String:
+		if t, err := time.Parse(time.RFC3339Nano, v.String()); err == nil {
+			if result, ok := compareTime(t, value); ok {
+				return result, true
+			}
+		}
+		return strings.Compare(v.String(), value), true
+	case reflect.Bool:
+		return compareBool(v.Bool(), value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareNumber(strconv.FormatInt(v.Int(), 10), value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return compareNumber(strconv.FormatUint(v.Uint(), 10), value)
+	case reflect.Float32:
+		return compareFloat(v.Float(), value, 32)
+	case reflect.Float64:
+		return compareFloat(v.Float(), value, 64)
+	}
+	return 0, false
+}
@@ -0,0 +1,151 @@
+package etsiparser
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testState string
+
+func TestCompareValue(t *testing.T) {
+	startTime := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	comparisons := []struct {
+		attribute interface{}
+		value     string
+		result    int
+		ok        bool
+	}{
+		{"abc", "abd", -1, true},
+		{testState("STARTED"), "STARTED", 0, true},
+		{float64(10), "9.5", 1, true},
+		{float64(10), "ten", 0, false},
+		{float64(10), "NaN", 0, false},
+		{float64(10), "Inf", 0, false},
+		{float64(10), "-Inf", 0, false},
+		{float64(2), "0x1p1", 0, false},
+		{float32(2), "0x1p1", 0, false},
+		{float64(2), "2.0", 0, true},
+		{float32(0.1), "0.1", 0, true},
+		{json.Number("12345678901234567890"), "12345678901234567891", -1, true},
+		{json.Number("1.0"), "1", 0, true},
+		{json.Number("0.30000000000000000001"), "0.3", 1, true},
+		{json.Number("1e400"), "1e399", 1, true},
+		{json.Number("10"), "0x0a", 0, false},
+		{json.Number("10"), "1/2", 0, false},
+		{int64(-3), "-3", 0, true},
+		{uint64(18446744073709551615), "18446744073709551614", 1, true},
+		{true, "true", 0, true},
+		{true, "false", 0, false},
+		{false, "0", 0, false},
+		{startTime, "2021-03-04T06:06:07+01:00", 0, true},
+		{&startTime, "2021-03-04T05:06:08Z", -1, true},
+		{"2021-03-04T05:06:07Z", "2021-03-04T05:06:07.5+00:00", -1, true},
+		{"2021-03-04T06:00:00+02:00", "2021-03-04T05:00:00Z", -1, true},
+		{"2021-03-04T05:06:07Z", "later", -1, true},
+		{nil, "null", 0, false},
+	}
+	for _, c := range comparisons {
+		result, ok := compareValue(c.attribute, c.value)
+
+		assert.Equal(t, c.ok, ok, "%v %s", c.attribute, c.value)
+		assert.Equal(t, c.result, result, "%v %s", c.attribute, c.value)
+	}
+}
+
+func TestFilterNonJSONNumbers(t *testing.T) {
+	input := `[{"id":1, "n":2}, {"id":2, "n":3}]`
+
+	assert.JSONEq(t, `[]`, applyFilter(t, "(eq,n,NaN)", input))
+	assert.JSONEq(t, input, applyFilter(t, "(neq,n,NaN)", input))
+	assert.JSONEq(t, `[]`, applyFilter(t, "(gt,n,0x1p1)", input))
+	assert.JSONEq(t, `[{"id":2, "n":3}]`, applyFilter(t, "(gt,n,2e0)", input))
+}
+
+func TestFilterTimestamps(t *testing.T) {
+	input := `
+	[
+	{"id":"op1", "startTime":"2021-03-04T05:06:07Z"},
+	{"id":"op2", "startTime":"2021-03-04T07:00:00+01:00"},
+	{"id":"op3", "startTime":"2021-03-05T00:00:00Z"}
+	]
+	`
+	expectedOutput := `
+	[
+	{"id":"op2", "startTime":"2021-03-04T07:00:00+01:00"}
+	]
+	`
+	assert.JSONEq(t, expectedOutput, applyFilter(t, "(gt,startTime,2021-03-04T05:30:00Z);(lt,startTime,2021-03-04T10:00:00+02:00)", input))
+}
+
+func TestFilterJSONNumbers(t *testing.T) {
+	f, err := ParseFilter("(gte,size,12345678901234567890)")
+	assert.Nil(t, err)
+	var payload []interface{}
+	decoder := json.NewDecoder(strings.NewReader(`[{"size":12345678901234567889}, {"size":12345678901234567890}, {"size":1.3e19}]`))
+	decoder.UseNumber()
+	assert.Nil(t, decoder.Decode(&payload))
+
+	var matched []interface{}
+	for _, item := range payload {
+		if f.Match(item) {
+			matched = append(matched, item)
+		}
+	}
+	assert.Equal(t, payload[1:], matched)
+}
+
+func TestFilterTypedStructs(t *testing.T) {
+	type operation struct {
+		ID        string    `json:"id"`
+		State     testState `json:"operationState"`
+		Retries   int       `json:"retries"`
+		Cancel    bool      `json:"isCancelPending"`
+		StartTime time.Time `json:"startTime"`
+	}
+	op := operation{ID: "op1", State: "PROCESSING", Retries: 3, StartTime: time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)}
+	filters := map[string]bool{
+		"(eq,operationState,PROCESSING)":           true,
+		"(cont,operationState,PROC)":               true,
+		"(gt,retries,2)":                           true,
+		"(lt,retries,2.5)":                         false,
+		"(eq,isCancelPending,false)":               true,
+		"(neq,isCancelPending,true)":               true,
+		"(gte,startTime,2021-03-04T05:06:07Z)":     true,
+		"(lt,startTime,2021-03-04T06:00:00+01:00)": false,
+	}
+	for filter, expected := range filters {
+		f, err := ParseFilter(filter)
+		assert.Nil(t, err, filter)
+
+		assert.Equal(t, expected, f.Match(op), filter)
+	}
+}
+
+func TestProxyFilterNumbers(t *testing.T) {
+	body := `[{"id":"1", "size":9007199254740993}, {"id":"2", "size":9007199254740992}]`
+	server, target := newUpstream(t, jsonHandler(http.StatusOK, "application/json", body))
+	defer server.Close()
+
+	w := httptest.NewRecorder()
+	NewProxy(target, "").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/x?filter=(gt,size,9007199254740992)&fields=id", nil))
+
+	assert.JSONEq(t, `[{"id":"1"}]`, w.Body.String())
+}
+
+func TestFilterBooleansOnlyForEquality(t *testing.T) {
+	input := `[{"id":1, "flag":true}, {"id":2, "flag":false}]`
+	flag := true
+
+	assert.JSONEq(t, `[{"id":1, "flag":true}]`, applyFilter(t, "(eq,flag,true)", input))
+	assert.JSONEq(t, `[{"id":2, "flag":false}]`, applyFilter(t, "(neq,flag,true)", input))
+	for _, operator := range []Operator{OperatorGreater, OperatorGreaterOrEqual, OperatorLess, OperatorLessOrEqual} {
+		assert.JSONEq(t, `[]`, applyFilter(t, "("+string(operator)+",flag,true)", input), operator)
+		assert.False(t, matchValue(operator, &flag, "true"), operator)
+	}
+}
@@ -0,0 +1,156 @@
+package etsiparser
+
+// Descriptors of the resources defined in ETSI GS NFV-SOL 002, SOL 003 and
+// SOL 005. They are registered in DefaultRegistry under their names.
+var (
+	VnfInstanceDescriptor = ResourceDescriptor{
+		Name: "VnfInstance",
+		DefaultExcluded: []string{
+			"vnfConfigurableProperties",
+			"vimConnectionInfo",
+			"instantiatedVnfInfo",
+			"metadata",
+			"extensions",
+		},
+		Mandatory: []string{
+			"id",
+			"vnfdId",
+			"vnfProvider",
+			"vnfProductName",
+			"vnfSoftwareVersion",
+			"vnfdVersion",
+			"instantiationState",
+			"_links",
+		},
+	}
+	VnfLcmOpOccDescriptor = ResourceDescriptor{
+		Name: "VnfLcmOpOcc",
+		DefaultExcluded: []string{
+			"operationParams",
+			"error",
+			"resourceChanges",
+			"changedInfo",
+			"changedExtConnectivity",
+		},
+		Mandatory: []string{
+			"id",
+			"operationState",
+			"stateEnteredTime",
+			"startTime",
+			"vnfInstanceId",
+			"operation",
+			"isAutomaticInvocation",
+			"isCancelPending",
+			"_links",
+		},
+	}
+	VnfPkgInfoDescriptor = ResourceDescriptor{
+		Name: "VnfPkgInfo",
+		DefaultExcluded: []string{
+			"softwareImages",
+			"additionalArtifacts",
+			"userDefinedData",
+			"checksum",
+			"onboardingFailureDetails",
+		},
+		Mandatory: []string{
+			"id",
+			"onboardingState",
+			"operationalState",
+			"usageState",
+			"_links",
+		},
+	}
+	NsInstanceDescriptor = ResourceDescriptor{
+		Name: "NsInstance",
+		DefaultExcluded: []string{
+			"vnfInstance",
+			"pnfInfo",
+			"virtualLinkInfo",
+			"vnffgInfo",
+			"sapInfo",
+			"nsScaleStatus",
+			"additionalAffinityOrAntiAffinityRules",
+		},
+		Mandatory: []string{
+			"id",
+			"nsInstanceName",
+			"nsInstanceDescription",
+			"nsdId",
+			"nsdInfoId",
+			"nsState",
+			"_links",
+		},
+	}
+	NsLcmOpOccDescriptor = ResourceDescriptor{
+		Name: "NsLcmOpOcc",
+		DefaultExcluded: []string{
+			"operationParams",
+			"error",
+			"resourceChanges",
+		},
+		Mandatory: []string{
+			"id",
+			"operationState",
+			"statusEnteredTime",
+			"nsInstanceId",
+			"lcmOperationType",
+			"startTime",
+			"isAutomaticInvocation",
+			"isCancelPending",
+			"_links",
+		},
+	}
+	PmJobDescriptor = ResourceDescriptor{
+		Name: "PmJob",
+		DefaultExcluded: []string{
+			"reports",
+		},
+		Mandatory: []string{
+			"id",
+			"objectType",
+			"objectInstanceIds",
+			"criteria",
+			"callbackUri",
+			"_links",
+		},
+	}
+	AlarmDescriptor = ResourceDescriptor{
+		Name: "Alarm",
+		Mandatory: []string{
+			"id",
+			"managedObjectId",
+			"alarmRaisedTime",
+			"ackState",
+			"perceivedSeverity",
+			"eventTime",
+			"eventType",
+			"probableCause",
+			"isRootCause",
+			"_links",
+		},
+	}
+	SubscriptionDescriptor = ResourceDescriptor{
+		Name: "Subscription",
+		Mandatory: []string{
+			"id",
+			"callbackUri",
+			"_links",
+		},
+	}
+)
+
+func init() {
+	for _, descriptor := range []ResourceDescriptor{
+		VnfInstanceDescriptor,
+		VnfLcmOpOccDescriptor,
+		VnfPkgInfoDescriptor,
+		NsInstanceDescriptor,
+		NsLcmOpOccDescriptor,
+		PmJobDescriptor,
+		AlarmDescriptor,
+		SubscriptionDescriptor,
+	} {
+		RegisterResource(descriptor)
+	}
+}
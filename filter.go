@@ -0,0 +1,240 @@
+package etsiparser
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// FilterParameter is the URI query parameter of attribute-based filters.
+const FilterParameter = "filter"
+
+// Operator is a comparison operator of an attribute-based filter expression.
+type Operator string
+
+const (
+	OperatorEqual          Operator = "eq"
+	OperatorNotEqual       Operator = "neq"
+	OperatorGreater        Operator = "gt"
+	OperatorGreaterOrEqual Operator = "gte"
+	OperatorLess           Operator = "lt"
+	OperatorLessOrEqual    Operator = "lte"
+	OperatorContains       Operator = "cont"
+	OperatorNotContains    Operator = "ncont"
+)
+
+// Expression is a single "(op,attribute,value1,value2,...)" term of a
+// filter. It holds if the attribute matches any of the values.
+type Expression struct {
+	Operator  Operator
+	Attribute []string
+	Values    []string
+}
+
+// Filter is an attribute-based filter as defined in ETSI GS NFV-SOL 013
+// clause 5.2. All of its expressions have to match.
+type Filter struct {
+	Expressions []Expression
+}
+
+// FilterSyntaxError describes why a filter string could not be parsed.
+type FilterSyntaxError struct {
+	Filter string
+	Offset int
+	Reason string
+}
+
+func (e *FilterSyntaxError) Error() string {
+	return fmt.Sprintf("invalid filter %q at offset %d: %s", e.Filter, e.Offset, e.Reason)
+}
+
+func parseExpression(filter string, start int, end int) (Expression, error) {
+	parts := splitUnquoted(filter[start:end], ',', false)
+	if len(parts) < 3 {
+		return Expression{}, &FilterSyntaxError{filter, start, "expected operator, attribute and value"}
+	}
+	operator := Operator(parts[0])
+	switch operator {
+	case OperatorEqual, OperatorNotEqual, OperatorGreater, OperatorGreaterOrEqual,
+		OperatorLess, OperatorLessOrEqual, OperatorContains, OperatorNotContains:
+	default:
+		return Expression{}, &FilterSyntaxError{filter, start, fmt.Sprintf("unknown operator %q", parts[0])}
+	}
+	attribute, err := splitAttributePath(parts[1], &selectorOptions{})
+	if err != nil {
+		return Expression{}, &FilterSyntaxError{filter, start + len(parts[0]) + 1, err.Error()}
+	}
+	offset := start + len(parts[0]) + len(parts[1]) + 2
+	values := make([]string, len(parts)-2)
+	for i, part := range parts[2:] {
+		value, err := unquoteValue(part)
+		if err != nil {
+			return Expression{}, &FilterSyntaxError{filter, offset, err.Error()}
+		}
+		values[i] = value
+		offset += len(part) + 1
+	}
+	return Expression{
+		Operator:  operator,
+		Attribute: attribute,
+		Values:    values,
+	}, nil
+}
+
+// ParseFilter parses the value of a "filter" URI query parameter, e.g.
+// "(eq,vnfdId,abc);(neq,instantiationState,NOT_INSTANTIATED)", as decoded by
+// url.Values. Values containing ",", "'", "(", ")" or ";" are enclosed in
+// single quotes with a single quote written as two, e.g. "'a,b'". A filter
+// that is still percent-encoded, i.e. starts with "%28" instead of "(", is
+// decoded first.
+func ParseFilter(filter string) (Filter, error) {
+	if !strings.HasPrefix(filter, "(") && strings.HasPrefix(strings.ToUpper(filter), "%28") {
+		decoded, err := url.QueryUnescape(filter)
+		if err != nil {
+			return Filter{}, &FilterSyntaxError{filter, 0, "invalid percent-encoding"}
+		}
+		filter = decoded
+	}
+	var f Filter
+	offset := 0
+	for {
+		if offset >= len(filter) || filter[offset] != '(' {
+			return Filter{}, &FilterSyntaxError{filter, offset, "expected '('"}
+		}
+		end := indexUnquoted(filter[offset:], ')', false)
+		if end < 0 {
+			return Filter{}, &FilterSyntaxError{filter, offset, "missing ')'"}
+		}
+		expression, err := parseExpression(filter, offset+1, offset+end)
+		if err != nil {
+			return Filter{}, err
+		}
+		f.Expressions = append(f.Expressions, expression)
+		offset += end + 1
+		if offset == len(filter) {
+			return f, nil
+		}
+		if filter[offset] != ';' {
+			return Filter{}, &FilterSyntaxError{filter, offset, "expected ';'"}
+		}
+		offset++
+	}
+}
+
+// ParseFilterQuery parses the "filter" parameters of a raw URI query, e.g.
+// URL.RawQuery, joining several of them with ";". Unlike url.ParseQuery it
+// accepts the unencoded ";" separating the expressions of a filter. It
+// returns nil if the query has no filter parameter.
+func ParseFilterQuery(rawQuery string) (*Filter, error) {
+	var filters []string
+	for _, parameter := range strings.Split(rawQuery, "&") {
+		key, value := parameter, ""
+		if i := strings.IndexByte(parameter, '='); i >= 0 {
+			key, value = parameter[:i], parameter[i+1:]
+		}
+		if key, err := url.QueryUnescape(key); err != nil || key != FilterParameter {
+			continue
+		}
+		decoded, err := url.QueryUnescape(value)
+		if err != nil {
+			return nil, &FilterSyntaxError{value, 0, "invalid percent-encoding"}
+		}
+		filters = append(filters, decoded)
+	}
+	if filters == nil {
+		return nil, nil
+	}
+	f, err := ParseFilter(strings.Join(filters, ";"))
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func collectAttributeValues(path []string, object interface{}) []interface{} {
+	switch o := object.(type) {
+	case []interface{}:
+		var values []interface{}
+		for _, item := range o {
+			values = append(values, collectAttributeValues(path, item)...)
+		}
+		return values
+	case map[string]interface{}:
+		if len(path) == 0 {
+			return []interface{}{o}
+		}
+		value, ok := o[path[0]]
+		if !ok {
+			return nil
+		}
+		return collectAttributeValues(path[1:], value)
+	}
+	if object == nil {
+		if len(path) == 0 {
+			return []interface{}{nil}
+		}
+		return nil
+	}
+	return collectAttributeValuesOf(path, reflect.ValueOf(object))
+}
+
+func matchValue(operator Operator, attribute interface{}, value string) bool {
+	if operator == OperatorContains {
+		a, ok := stringValue(attribute)
+		return ok && strings.Contains(a, value)
+	}
+	if operator != OperatorEqual && isBoolValue(attribute) {
+		return false
+	}
+	result, ok := compareValue(attribute, value)
+	if !ok {
+		return false
+	}
+	switch operator {
+	case OperatorEqual:
+		return result == 0
+	case OperatorGreater:
+		return result > 0
+	case OperatorGreaterOrEqual:
+		return result >= 0
+	case OperatorLess:
+		return result < 0
+	case OperatorLessOrEqual:
+		return result <= 0
+	}
+	return false
+}
+
+// Match reports whether the expression holds for the given object. When the
+// attribute path crosses arrays, "neq" and "ncont" hold if no element matches
+// any of the values and all other operators hold if any element matches any
+// of the values.
+func (e Expression) Match(object interface{}) bool {
+	operator, negated := e.Operator, false
+	switch operator {
+	case OperatorNotEqual:
+		operator, negated = OperatorEqual, true
+	case OperatorNotContains:
+		operator, negated = OperatorContains, true
+	}
+	for _, attribute := range collectAttributeValues(e.Attribute, object) {
+		for _, value := range e.Values {
+			if matchValue(operator, attribute, value) {
+				return !negated
+			}
+		}
+	}
+	return negated
+}
+
+// Match reports whether all expressions of the filter hold for the given
+// object.
+func (f Filter) Match(object interface{}) bool {
+	for _, expression := range f.Expressions {
+		if !expression.Match(object) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,245 @@
+package etsiparser
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func applyFilter(t *testing.T, filter string, input string) string {
+	f, err := ParseFilter(filter)
+	assert.Nil(t, err)
+
+	var payload []interface{}
+	err = json.Unmarshal([]byte(input), &payload)
+	assert.Nil(t, err)
+
+	matched := []interface{}{}
+	for _, item := range payload {
+		if f.Match(item) {
+			matched = append(matched, item)
+		}
+	}
+	res, err := json.MarshalIndent(matched, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(res))
+	return string(res)
+}
+
+func TestParseFilter(t *testing.T) {
+	f, err := ParseFilter("(eq,vnfdId,abc);(neq,instantiatedVnfInfo/vnfState,STARTED)")
+
+	assert.Nil(t, err)
+	assert.Equal(t, Filter{Expressions: []Expression{
+		{Operator: OperatorEqual, Attribute: []string{"vnfdId"}, Values: []string{"abc"}},
+		{Operator: OperatorNotEqual, Attribute: []string{"instantiatedVnfInfo", "vnfState"}, Values: []string{"STARTED"}},
+	}}, f)
+}
+
+func TestParseFilterValueList(t *testing.T) {
+	f, err := ParseFilter("(cont,vnfInstanceName,web,db)")
+
+	assert.Nil(t, err)
+	assert.Equal(t, Filter{Expressions: []Expression{
+		{Operator: OperatorContains, Attribute: []string{"vnfInstanceName"}, Values: []string{"web", "db"}},
+	}}, f)
+}
+
+func TestParseFilterSyntaxErrors(t *testing.T) {
+	filters := map[string]int{
+		"":                   0,
+		"eq,vnfdId,abc":      0,
+		"(eq,vnfdId,abc":     0,
+		"(eq,vnfdId)":        1,
+		"(like,vnfdId,abc)":  1,
+		"(eq,,abc)":          4,
+		"(eq,vnfdId,abc)x":   15,
+		"(eq,vnfdId,abc);":   16,
+		"(eq,vnfdId,abc);;":  16,
+		"(eq,vnfdId,abc),(x": 15,
+	}
+	for filter, offset := range filters {
+		_, err := ParseFilter(filter)
+
+		syntaxError, ok := err.(*FilterSyntaxError)
+		if assert.True(t, ok, filter) {
+			assert.Equal(t, offset, syntaxError.Offset, filter)
+		}
+	}
+}
+
+func TestFilterEqual(t *testing.T) {
+	input := `
+	[
+	{"id":123, "weight":100, "parts":[{"id":1, "color":"red"}, {"id":2, "color":"green"}]},
+	{"id":456, "weight":500, "parts":[{"id":3, "color":"green"}, {"id":4, "color":"blue"}]}
+	]
+	`
+	expectedOutput := `
+	[
+	{"id":456, "weight":500, "parts":[{"id":3, "color":"green"}, {"id":4, "color":"blue"}]}
+	]
+	`
+	assert.JSONEq(t, expectedOutput, applyFilter(t, "(eq,weight,500)", input))
+}
+
+func TestFilterNotEqual(t *testing.T) {
+	input := `
+	[
+	{"id":123, "weight":100, "parts":[{"id":1, "color":"red"}, {"id":2, "color":"green"}]},
+	{"id":456, "weight":500, "parts":[{"id":3, "color":"green"}, {"id":4, "color":"blue"}]}
+	]
+	`
+	expectedOutput := `
+	[
+	{"id":123, "weight":100, "parts":[{"id":1, "color":"red"}, {"id":2, "color":"green"}]}
+	]
+	`
+	assert.JSONEq(t, expectedOutput, applyFilter(t, "(neq,weight,500)", input))
+}
+
+func TestFilterNestedListAttribute(t *testing.T) {
+	input := `
+	[
+	{"id":123, "weight":100, "parts":[{"id":1, "color":"red"}, {"id":2, "color":"green"}]},
+	{"id":456, "weight":500, "parts":[{"id":3, "color":"green"}, {"id":4, "color":"blue"}]}
+	]
+	`
+	expectedOutput := `
+	[
+	{"id":123, "weight":100, "parts":[{"id":1, "color":"red"}, {"id":2, "color":"green"}]}
+	]
+	`
+	assert.JSONEq(t, expectedOutput, applyFilter(t, "(eq,parts/color,red)", input))
+}
+
+func TestFilterMultipleExpressions(t *testing.T) {
+	input := `
+	[
+	{"id":123, "weight":100, "parts":[{"id":1, "color":"red"}, {"id":2, "color":"green"}]},
+	{"id":456, "weight":500, "parts":[{"id":3, "color":"green"}, {"id":4, "color":"blue"}]}
+	]
+	`
+	expectedOutput := `
+	[
+	{"id":456, "weight":500, "parts":[{"id":3, "color":"green"}, {"id":4, "color":"blue"}]}
+	]
+	`
+	assert.JSONEq(t, expectedOutput, applyFilter(t, "(eq,parts/color,green);(neq,id,123)", input))
+}
+
+func TestFilterNonExistingAttribute(t *testing.T) {
+	input := `
+	[
+	{"id":123, "weight":100},
+	{"id":456, "weight":500}
+	]
+	`
+	assert.JSONEq(t, `[]`, applyFilter(t, "(eq,cores,4)", input))
+	assert.JSONEq(t, input, applyFilter(t, "(neq,cores,4)", input))
+}
+
+func TestFilterComparisonOperators(t *testing.T) {
+	input := `
+	[
+	{"id":123, "weight":100},
+	{"id":456, "weight":500},
+	{"id":789, "weight":900}
+	]
+	`
+	assert.JSONEq(t, `[{"id":789, "weight":900}]`, applyFilter(t, "(gt,weight,500)", input))
+	assert.JSONEq(t, `[{"id":456, "weight":500}, {"id":789, "weight":900}]`, applyFilter(t, "(gte,weight,500)", input))
+	assert.JSONEq(t, `[{"id":123, "weight":100}]`, applyFilter(t, "(lt,weight,500)", input))
+	assert.JSONEq(t, `[{"id":123, "weight":100}, {"id":456, "weight":500}]`, applyFilter(t, "(lte,weight,500)", input))
+	assert.JSONEq(t, `[]`, applyFilter(t, "(gt,weight,heavy)", input))
+}
+
+func TestFilterContains(t *testing.T) {
+	input := `
+	[
+	{"id":123, "name":"web-frontend"},
+	{"id":456, "name":"db-backend"},
+	{"id":789, "weight":900}
+	]
+	`
+	assert.JSONEq(t, `[{"id":123, "name":"web-frontend"}]`, applyFilter(t, "(cont,name,front)", input))
+	assert.JSONEq(t, `[{"id":456, "name":"db-backend"}, {"id":789, "weight":900}]`, applyFilter(t, "(ncont,name,front)", input))
+}
+
+func TestFilterValueList(t *testing.T) {
+	input := `
+	[
+	{"id":123, "weight":100},
+	{"id":456, "weight":500},
+	{"id":789, "weight":900}
+	]
+	`
+	assert.JSONEq(t, `[{"id":123, "weight":100}, {"id":789, "weight":900}]`, applyFilter(t, "(eq,weight,100,900)", input))
+	assert.JSONEq(t, `[{"id":456, "weight":500}]`, applyFilter(t, "(neq,weight,100,900)", input))
+}
+
+func TestFilterListAttributeSemantics(t *testing.T) {
+	input := `
+	[
+	{"id":123, "parts":[{"id":1, "color":"red"}, {"id":2, "color":"green"}]},
+	{"id":456, "parts":[{"id":3, "color":"green"}, {"id":4, "color":"blue"}]},
+	{"id":789, "parts":[]}
+	]
+	`
+	expectedOutput := `
+	[
+	{"id":123, "parts":[{"id":1, "color":"red"}, {"id":2, "color":"green"}]},
+	{"id":456, "parts":[{"id":3, "color":"green"}, {"id":4, "color":"blue"}]}
+	]
+	`
+	assert.JSONEq(t, expectedOutput, applyFilter(t, "(eq,parts/color,green)", input))
+
+	expectedOutput = `
+	[
+	{"id":456, "parts":[{"id":3, "color":"green"}, {"id":4, "color":"blue"}]},
+	{"id":789, "parts":[]}
+	]
+	`
+	assert.JSONEq(t, expectedOutput, applyFilter(t, "(neq,parts/color,red)", input))
+
+	expectedOutput = `
+	[
+	{"id":789, "parts":[]}
+	]
+	`
+	assert.JSONEq(t, expectedOutput, applyFilter(t, "(ncont,parts/color,e)", input))
+	assert.JSONEq(t, `[{"id":456, "parts":[{"id":3, "color":"green"}, {"id":4, "color":"blue"}]}]`, applyFilter(t, "(gte,parts/id,4)", input))
+}
+
+func TestFilterListOfScalarsAttribute(t *testing.T) {
+	input := `
+	[
+	{"id":123, "tags":["web", "public"]},
+	{"id":456, "tags":["db"]}
+	]
+	`
+	assert.JSONEq(t, `[{"id":123, "tags":["web", "public"]}]`, applyFilter(t, "(eq,tags,public)", input))
+	assert.JSONEq(t, `[{"id":456, "tags":["db"]}]`, applyFilter(t, "(neq,tags,public)", input))
+}
+
+func TestFilterConcreteContainerTypes(t *testing.T) {
+	f, err := ParseFilter("(eq,parts/color,green);(eq,metadata/site,athens);(eq,tags,public)")
+	assert.Nil(t, err)
+
+	payload := map[string]interface{}{
+		"parts": []map[string]interface{}{
+			{"id": 1, "color": "red"},
+			{"id": 2, "color": "green"},
+		},
+		"metadata": map[string]string{"site": "athens"},
+		"tags":     []string{"web", "public"},
+	}
+	assert.True(t, f.Match(payload))
+
+	payload["metadata"] = map[string]string{"site": "patras"}
+	assert.False(t, f.Match(payload))
+}
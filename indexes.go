@@ -0,0 +1,174 @@
+package etsiparser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Array index segments are a non-standard extension of the SOL013 attribute
+// paths, enabled by the ArrayIndexes option. A segment "[i]" addresses the
+// element at index i of an array, "[i:j]" the elements from index i up to
+// but excluding j. Either bound of a range may be omitted, e.g. "[:3]" for
+// the first three elements. The predicate segments enabled by the
+// ArrayPredicates option are described in predicates.go.
+
+type indexRange struct {
+	start int
+	end   int // -1 for ranges open to the end of the array
+}
+
+func isIndexSegment(segment string) bool {
+	return strings.HasPrefix(segment, "[") && strings.HasSuffix(segment, "]")
+}
+
+func parseIndex(s string, open int) (int, bool) {
+	if s == "" {
+		return open, true
+	}
+	if s[0] == '+' || s[0] == '-' {
+		return 0, false
+	}
+	i, err := strconv.Atoi(s)
+	return i, err == nil
+}
+
+func parseIndexSegment(segment string) (indexRange, bool) {
+	if !isIndexSegment(segment) || len(segment) < 3 {
+		return indexRange{}, false
+	}
+	inner := segment[1 : len(segment)-1]
+	colon := strings.IndexByte(inner, ':')
+	if colon < 0 {
+		i, ok := parseIndex(inner, 0)
+		return indexRange{i, i + 1}, ok && inner != ""
+	}
+	start, startOK := parseIndex(inner[:colon], 0)
+	end, endOK := parseIndex(inner[colon+1:], -1)
+	if !startOK || !endOK || end != -1 && end < start {
+		return indexRange{}, false
+	}
+	return indexRange{start, end}, true
+}
+
+func (r indexRange) contains(i int) bool {
+	return i >= r.start && (r.end == -1 || i < r.end)
+}
+
+// elementSegment is a compiled array index or predicate segment together
+// with the attributes it applies to the addressed elements. It is the value
+// of the segment's key in an attribute map, so that it is parsed only once.
+type elementSegment struct {
+	indexes       indexRange
+	predicate     *Filter
+	attributesMap map[string]interface{}
+}
+
+func (s elementSegment) matches(i int, item interface{}) bool {
+	if s.predicate != nil {
+		return s.predicate.Match(item)
+	}
+	return s.indexes.contains(i)
+}
+
+func isElementSegment(segment string, options *selectorOptions) bool {
+	return options.arrayPredicates && isPredicateSegment(segment) ||
+		options.arrayIndexes && isIndexSegment(segment)
+}
+
+// parseElementSegments parses the element segments of the attribute path.
+// The result holds nil for the other segments.
+func parseElementSegments(path string, segments []attributeSegment, options *selectorOptions) ([]*elementSegment, error) {
+	elements := make([]*elementSegment, len(segments))
+	for i, s := range segments {
+		segment := s.name
+		switch {
+		case s.quoted:
+		case options.arrayPredicates && isPredicateSegment(segment):
+			predicate, err := parsePredicateSegment(segment)
+			if err != nil {
+				return nil, &PathError{path, i, "invalid predicate: " + err.(*FilterSyntaxError).Reason}
+			}
+			elements[i] = &elementSegment{predicate: &predicate}
+		case options.arrayIndexes && isIndexSegment(segment):
+			r, ok := parseIndexSegment(segment)
+			if !ok {
+				return nil, &PathError{path, i, "invalid array index"}
+			}
+			elements[i] = &elementSegment{indexes: r}
+		}
+	}
+	return elements, nil
+}
+
+// mergeElementSegments returns the element segment with the union of the
+// attributes of a and b, which hold the same segment.
+func mergeElementSegments(a *elementSegment, b *elementSegment) *elementSegment {
+	merged := *a
+	merged.attributesMap = mergeAttributesMaps(a.attributesMap, b.attributesMap)
+	return &merged
+}
+
+// arrayAttributes are the attributes that apply to the elements of an array:
+// the plain attributes apply to every element, those of the element segments
+// only to the elements they address.
+type arrayAttributes struct {
+	plain    map[string]interface{}
+	segments []*elementSegment
+}
+
+func newArrayAttributes(attributesMap map[string]interface{}) arrayAttributes {
+	a := arrayAttributes{plain: attributesMap}
+	for _, value := range attributesMap {
+		if segment, ok := value.(*elementSegment); ok {
+			a.segments = append(a.segments, segment)
+		}
+	}
+	if len(a.segments) > 0 {
+		a.plain = make(map[string]interface{}, len(attributesMap)-len(a.segments))
+		for field, value := range attributesMap {
+			if _, ok := value.(*elementSegment); !ok {
+				a.plain[field] = value
+			}
+		}
+	}
+	return a
+}
+
+// forElement returns the attributes that apply to the element item at index
+// i: the plain attributes merged with those of the element segments that
+// address it. whole reports that an element segment addresses the element
+// as a whole.
+func (a arrayAttributes) forElement(i int, item interface{}) (map[string]interface{}, bool) {
+	result := a.plain
+	for _, segment := range a.segments {
+		if !segment.matches(i, item) {
+			continue
+		}
+		if len(segment.attributesMap) == 0 {
+			return nil, true
+		}
+		if len(result) == 0 {
+			result = segment.attributesMap
+		} else {
+			result = mergeAttributesMaps(result, segment.attributesMap)
+		}
+	}
+	return result, false
+}
+
+func excludeElementFields(attributes arrayAttributes, include arrayAttributes, list []interface{}, options *selectorOptions) []interface{} {
+	resultList := make([]interface{}, 0, len(list))
+	for i, item := range list {
+		itemAttributesMap, excludeWhole := attributes.forElement(i, item)
+		itemIncludeMap, includeWhole := include.forElement(i, item)
+		switch {
+		case !excludeWhole || includeWhole:
+			resultList = append(resultList, excludeFieldsRecursively(itemAttributesMap, itemIncludeMap, item, options))
+		case len(itemIncludeMap) > 0:
+			if value := selectFieldsRecursively(itemIncludeMap, item, options); value != nil {
+				resultList = append(resultList, value)
+			}
+		}
+	}
+	return resultList
+}
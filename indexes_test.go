@@ -0,0 +1,120 @@
+package etsiparser
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const vnfcResourcesInput = `
+	{"id":"123", "vnfcResourceInfo":[
+	 {"id":"vnfc1", "vduId":"VDU1", "computeResource":{"resourceId":"vm1"}},
+	 {"id":"vnfc2", "vduId":"VDU1", "computeResource":{"resourceId":"vm2"}},
+	 {"id":"vnfc3", "vduId":"VDU2", "computeResource":{"resourceId":"vm3"}},
+	 {"id":"vnfc4", "vduId":"VDU2", "computeResource":{"resourceId":"vm4"}}
+	]}
+	`
+
+func unmarshalPayload(t *testing.T, input string) interface{} {
+	var payload interface{}
+	err := json.Unmarshal([]byte(input), &payload)
+	assert.Nil(t, err)
+	return payload
+}
+
+func TestParseIndexSegment(t *testing.T) {
+	segments := map[string]indexRange{
+		"[0]":   {0, 1},
+		"[12]":  {12, 13},
+		"[:3]":  {0, 3},
+		"[1:3]": {1, 3},
+		"[2:]":  {2, -1},
+		"[:]":   {0, -1},
+	}
+	for segment, expectedRange := range segments {
+		r, ok := parseIndexSegment(segment)
+
+		assert.True(t, ok, segment)
+		assert.Equal(t, expectedRange, r, segment)
+	}
+	for _, segment := range []string{"[]", "[a]", "[-1]", "[+1]", "[3:1]", "[1:2:3]", "0", "[0"} {
+		_, ok := parseIndexSegment(segment)
+
+		assert.False(t, ok, segment)
+	}
+}
+
+func TestSelectArrayIndex(t *testing.T) {
+	attributes := []string{"id", "vnfcResourceInfo/[0]/computeResource"}
+	expectedOutput := `{"id":"123", "vnfcResourceInfo":[{"computeResource":{"resourceId":"vm1"}}]}`
+
+	modifiedPayload := SelectFields(attributes, unmarshalPayload(t, vnfcResourcesInput), ArrayIndexes())
+
+	assert.JSONEq(t, expectedOutput, marshalPayload(modifiedPayload))
+}
+
+func TestSelectArrayRange(t *testing.T) {
+	attributes := []string{"vnfcResourceInfo/[:2]", "vnfcResourceInfo/[3:]/id", "vnfcResourceInfo/vduId"}
+	expectedOutput := `
+	{"vnfcResourceInfo":[
+	 {"id":"vnfc1", "vduId":"VDU1", "computeResource":{"resourceId":"vm1"}},
+	 {"id":"vnfc2", "vduId":"VDU1", "computeResource":{"resourceId":"vm2"}},
+	 {"vduId":"VDU2"},
+	 {"id":"vnfc4", "vduId":"VDU2"}
+	]}
+	`
+	modifiedPayload := SelectFields(attributes, unmarshalPayload(t, vnfcResourcesInput), ArrayIndexes())
+
+	assert.JSONEq(t, expectedOutput, marshalPayload(modifiedPayload))
+}
+
+func TestSelectArrayIndexWithoutOption(t *testing.T) {
+	attributes := []string{"id", "vnfcResourceInfo/[0]/computeResource"}
+
+	modifiedPayload := SelectFields(attributes, unmarshalPayload(t, vnfcResourcesInput))
+
+	assert.JSONEq(t, `{"id":"123"}`, marshalPayload(modifiedPayload))
+}
+
+func TestSelectArrayIndexOutOfRange(t *testing.T) {
+	attributes := []string{"id", "vnfcResourceInfo/[7]"}
+
+	modifiedPayload := SelectFields(attributes, unmarshalPayload(t, vnfcResourcesInput), ArrayIndexes())
+
+	assert.JSONEq(t, `{"id":"123"}`, marshalPayload(modifiedPayload))
+}
+
+func TestExcludeArrayIndex(t *testing.T) {
+	attributes := []string{"vnfcResourceInfo/[1:]", "vnfcResourceInfo/[0]/computeResource"}
+	expectedOutput := `{"id":"123", "vnfcResourceInfo":[{"id":"vnfc1", "vduId":"VDU1"}]}`
+
+	modifiedPayload := ExcludeFields(attributes, unmarshalPayload(t, vnfcResourcesInput), ArrayIndexes())
+
+	assert.JSONEq(t, expectedOutput, marshalPayload(modifiedPayload))
+}
+
+func TestExcludeArrayIndexKeepsMandatory(t *testing.T) {
+	s, err := CompileExclude([]string{"vnfcResourceInfo/[:3]"}, ArrayIndexes())
+	assert.Nil(t, err)
+	s = s.WithMandatory([]string{"vnfcResourceInfo/id"})
+	expectedOutput := `
+	{"id":"123", "vnfcResourceInfo":[
+	 {"id":"vnfc1"},
+	 {"id":"vnfc2"},
+	 {"id":"vnfc3"},
+	 {"id":"vnfc4", "vduId":"VDU2", "computeResource":{"resourceId":"vm4"}}
+	]}
+	`
+	assert.JSONEq(t, expectedOutput, marshalPayload(s.Apply(unmarshalPayload(t, vnfcResourcesInput))))
+}
+
+func TestCompileInvalidArrayIndex(t *testing.T) {
+	_, err := CompileSelect([]string{"vnfcResourceInfo/[a]/id"}, ArrayIndexes())
+
+	assert.Equal(t, &PathError{"vnfcResourceInfo/[a]/id", 1, "invalid array index"}, err)
+
+	_, err = CompileSelect([]string{"vnfcResourceInfo/[a]/id"})
+
+	assert.Nil(t, err)
+}
@@ -0,0 +1,45 @@
+package etsiparser
+
+// FilterItems returns the items that match the filter, in their original
+// order. items itself is not modified.
+func FilterItems[T any](items []T, f Filter) []T {
+	result := make([]T, 0, len(items))
+	for _, item := range items {
+		if f.Match(item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// SelectItems returns the representation of every item selected by s. Like
+// Apply on an array, it leaves out the items of which nothing is selected.
+func SelectItems[T any](items []T, s *Selector) []interface{} {
+	result := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		if value := s.Apply(item); value != nil {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// QueryItems returns the representations selected by s of the items that
+// match f, as a list endpoint answers a request with filter and attribute
+// selector parameters. A nil filter matches every item and a nil selector
+// returns the items unchanged. Items of which nothing is selected are left
+// out, as in SelectItems.
+func QueryItems[T any](items []T, f *Filter, s *Selector) []interface{} {
+	result := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		if f != nil && !f.Match(item) {
+			continue
+		}
+		if s == nil {
+			result = append(result, item)
+		} else if value := s.Apply(item); value != nil {
+			result = append(result, value)
+		}
+	}
+	return result
+}
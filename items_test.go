@@ -0,0 +1,64 @@
+package etsiparser
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterItems(t *testing.T) {
+	instances := testVnfInstances()
+	f, err := ParseFilter("(eq,instantiationState,NOT_INSTANTIATED)")
+	assert.Nil(t, err)
+
+	assert.Equal(t, instances[1:], FilterItems(instances, f))
+	assert.Equal(t, testVnfInstances(), instances)
+
+	f, err = ParseFilter("(eq,id,000)")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []testVnfInstance{}, FilterItems(instances, f))
+}
+
+func TestSelectItems(t *testing.T) {
+	s, err := CompileSelect([]string{"id", "metadata/owner"})
+	assert.Nil(t, err)
+
+	assert.JSONEq(t, `[{"id":"123", "metadata":{"owner":"alice"}}, {"id":"456", "metadata":{"owner":"bob"}}]`, marshalPayload(SelectItems(testVnfInstances(), s)))
+}
+
+func TestQueryItems(t *testing.T) {
+	query, _ := url.ParseQuery("exclude_default&fields=instantiatedVnfInfo/vnfState")
+	s, err := ParseResourceSelector("VnfInstance", query)
+	assert.Nil(t, err)
+	f, err := ParseFilter("(neq,instantiationState,NOT_INSTANTIATED)")
+	assert.Nil(t, err)
+	expectedOutput := `
+	[
+	{"id":"123", "vnfdId":"abc", "instantiationState":"INSTANTIATED",
+	 "instantiatedVnfInfo":{"vnfState":"STARTED"}, "_links":{"self":{"href":"/vnf_instances/123"}}}
+	]
+	`
+	assert.JSONEq(t, expectedOutput, marshalPayload(QueryItems(testVnfInstances(), &f, s)))
+
+	instances := testVnfInstances()
+	assert.Equal(t, []interface{}{instances[0], instances[1]}, QueryItems(instances, nil, nil))
+	assert.Equal(t, []interface{}{instances[0]}, QueryItems(instances, &f, nil))
+}
+
+func TestItemsWithoutSelectedAttributes(t *testing.T) {
+	instances := testVnfInstances()
+	s, err := CompileSelect([]string{"nope"})
+	assert.Nil(t, err)
+
+	assert.Equal(t, []interface{}{}, SelectItems(instances, s))
+	assert.Equal(t, `[]`, marshalPayload(QueryItems(instances, nil, s)))
+	assert.Nil(t, s.Apply(instances))
+
+	s, err = CompileSelect([]string{"instantiatedVnfInfo/vnfState"})
+	assert.Nil(t, err)
+
+	assert.JSONEq(t, marshalPayload(s.Apply(instances)), marshalPayload(QueryItems(instances, nil, s)))
+	assert.Len(t, SelectItems(instances, s), 1)
+}
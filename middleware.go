@@ -0,0 +1,137 @@
+package etsiparser
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// selectorResponseWriter buffers successful JSON responses so that the
+// selector can be applied to them. All other responses are written through.
+type selectorResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	buffering   bool
+	body        bytes.Buffer
+}
+
+func (w *selectorResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = status
+	w.buffering = status >= 200 && status < 300 && status != http.StatusNoContent &&
+		isJSONContentType(w.Header().Get("Content-Type")) &&
+		w.Header().Get("Content-Encoding") == ""
+	if !w.buffering {
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (w *selectorResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.buffering {
+		return w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// keepContainer returns result, or an empty array or object in place of a
+// nil result for an array or object, so that a response keeps its top-level
+// container even if nothing is selected.
+func keepContainer(data interface{}, result interface{}) interface{} {
+	if result != nil {
+		return result
+	}
+	switch data.(type) {
+	case []interface{}:
+		return []interface{}{}
+	case map[string]interface{}:
+		return map[string]interface{}{}
+	}
+	return nil
+}
+
+// transformJSON returns body with transform applied to the document it
+// holds, or body itself if it is not a single JSON document.
+func transformJSON(body []byte, transform func(interface{}) interface{}) []byte {
+	var data interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil || decoder.More() {
+		return body
+	}
+	transformed, err := json.Marshal(transform(data))
+	if err != nil {
+		return body
+	}
+	return transformed
+}
+
+func (w *selectorResponseWriter) finish(s *Selector) {
+	if !w.buffering {
+		return
+	}
+	if s.changesNothing() {
+		w.ResponseWriter.WriteHeader(w.status)
+		w.ResponseWriter.Write(w.body.Bytes())
+		return
+	}
+	body := transformJSON(w.body.Bytes(), func(data interface{}) interface{} {
+		return keepContainer(data, s.Apply(data))
+	})
+	if !bytes.Equal(body, w.body.Bytes()) {
+		w.Header().Del("ETag")
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.Write(body)
+}
+
+func parseRequestSelector(resource string, query url.Values) (*Selector, error) {
+	if resource == "" {
+		return ParseSelector(query)
+	}
+	return ParseResourceSelector(resource, query)
+}
+
+// SelectorHandler returns a handler that applies the all_fields, fields,
+// exclude_fields and exclude_default parameters of GET requests to the JSON
+// responses of next. resource names a resource type registered in
+// DefaultRegistry, or is empty for resources without default-excluded and
+// mandatory attributes. Invalid parameters are answered with a "400 Bad
+// Request" ProblemDetails. Responses that are not successful, not JSON or
+// content-encoded are passed through untouched, as are responses the
+// selector does not change. The ETag of a modified response is removed.
+func SelectorHandler(resource string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+		s, err := parseRequestSelector(resource, r.URL.Query())
+		if err != nil {
+			WriteProblemDetails(w, NewProblemDetails(err, r.URL.RequestURI()))
+			return
+		}
+		sw := &selectorResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r)
+		sw.finish(s)
+	})
+}
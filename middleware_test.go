@@ -0,0 +1,147 @@
+package etsiparser
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func jsonHandler(status int, contentType string, body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	})
+}
+
+func TestSelectorHandlerFields(t *testing.T) {
+	h := SelectorHandler("", jsonHandler(http.StatusOK, "application/json", vnfInstancesInput))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/vnf_instances?fields=id,vnfdId", nil))
+	expectedOutput := `
+	[
+	{"id":"123", "vnfdId":"abc"},
+	{"id":"456", "vnfdId":"def"}
+	]
+	`
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, expectedOutput, w.Body.String())
+	assert.Equal(t, strconv.Itoa(w.Body.Len()), w.Header().Get("Content-Length"))
+}
+
+func TestSelectorHandlerResourceDefaults(t *testing.T) {
+	h := SelectorHandler("VnfInstance", jsonHandler(http.StatusOK, "application/json; charset=utf-8", vnfInstancesInput))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/vnf_instances", nil))
+	expectedOutput := `
+	[
+	{"id":"123", "vnfdId":"abc", "instantiationState":"INSTANTIATED", "_links":{"self":{"href":"/vnf_instances/123"}}},
+	{"id":"456", "vnfdId":"def", "instantiationState":"NOT_INSTANTIATED", "_links":{"self":{"href":"/vnf_instances/456"}}}
+	]
+	`
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, expectedOutput, w.Body.String())
+	assert.Equal(t, strconv.Itoa(w.Body.Len()), w.Header().Get("Content-Length"))
+}
+
+func TestSelectorHandlerPreservesNumbers(t *testing.T) {
+	body := `{"id":"123", "size":12345678901234567890, "ratio":0.10}`
+	h := SelectorHandler("", jsonHandler(http.StatusOK, "application/json", body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/x?fields=size,ratio", nil))
+
+	assert.Equal(t, `{"ratio":0.10,"size":12345678901234567890}`, w.Body.String())
+}
+
+func TestSelectorHandlerPassThrough(t *testing.T) {
+	handlers := map[string]http.Handler{
+		"non-JSON":  jsonHandler(http.StatusOK, "text/plain", vnfInstancesInput),
+		"not found": jsonHandler(http.StatusNotFound, "application/json", vnfInstancesInput),
+		"invalid":   jsonHandler(http.StatusOK, "application/json", vnfInstancesInput+"{"),
+		"no header": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(vnfInstancesInput)) }),
+		"problem":   jsonHandler(http.StatusConflict, ProblemDetailsContentType, vnfInstancesInput),
+		"gzip-encoded": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Write([]byte(vnfInstancesInput))
+		}),
+	}
+	for name, handler := range handlers {
+		w := httptest.NewRecorder()
+		SelectorHandler("", handler).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/x?fields=id", nil))
+
+		assert.True(t, strings.HasPrefix(w.Body.String(), vnfInstancesInput), name)
+	}
+}
+
+func TestSelectorHandlerIgnoresOtherMethods(t *testing.T) {
+	h := SelectorHandler("", jsonHandler(http.StatusCreated, "application/json", vnfInstancesInput))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/x?fields=id&all_fields", strings.NewReader("{}")))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, vnfInstancesInput, w.Body.String())
+}
+
+func TestSelectorHandlerInvalidParameters(t *testing.T) {
+	called := false
+	h := SelectorHandler("", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/x?fields=id&exclude_fields=_links", nil))
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, ProblemDetailsContentType, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"title":"Conflicting attribute selector parameters",
+		"status":400,
+		"detail":"parameter \"exclude_fields\" cannot be combined with \"fields\"",
+		"instance":"/x?fields=id&exclude_fields=_links"
+	}`, w.Body.String())
+}
+
+func TestSelectorHandlerRemovesETag(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(vnfInstancesInput))
+	})
+	w := httptest.NewRecorder()
+	SelectorHandler("", handler).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/x?fields=id", nil))
+
+	assert.Equal(t, "", w.Header().Get("ETag"))
+}
+
+func TestSelectorHandlerKeepsUnchangedResponses(t *testing.T) {
+	body := `{"name":"<b>", "id":"123"}`
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(body))
+	})
+	for _, uri := range []string{"/x", "/x?all_fields"} {
+		w := httptest.NewRecorder()
+		SelectorHandler("", handler).ServeHTTP(w, httptest.NewRequest(http.MethodGet, uri, nil))
+
+		assert.Equal(t, `"v1"`, w.Header().Get("ETag"), uri)
+		assert.Equal(t, body, w.Body.String(), uri)
+	}
+}
+
+func TestSelectorHandlerKeepsEmptyContainers(t *testing.T) {
+	bodies := map[string]string{
+		vnfInstancesInput: `[]`,
+		`{"id":"123"}`:    `{}`,
+	}
+	for body, expected := range bodies {
+		w := httptest.NewRecorder()
+		SelectorHandler("", jsonHandler(http.StatusOK, "application/json", body)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/x?fields=nope", nil))
+
+		assert.Equal(t, expected, w.Body.String())
+	}
+}
@@ -1,33 +1,140 @@
 package etsiparser
 
 import (
+	"fmt"
+	"reflect"
 	"strings"
 )
 
-func selectFieldsRecursively(attributesMap map[string]interface{}, object interface{}) interface{} {
+// PathError describes a malformed attribute path.
+type PathError struct {
+	Path    string
+	Segment int
+	Reason  string
+}
+
+func (e *PathError) Error() string {
+	return fmt.Sprintf("invalid attribute path %q at segment %d: %s", e.Path, e.Segment, e.Reason)
+}
+
+// wildcardSegment is an attribute path segment that matches every attribute
+// of an object, e.g. "extensions/*/version".
+const wildcardSegment = "*"
+
+// specialPrefix marks the keys of wildcard and element segments in attribute
+// maps, so that they are told apart from attributes with the same name. Not
+// being valid UTF-8, it never starts an attribute name decoded from JSON.
+const (
+	specialPrefix = "\xff"
+	wildcardKey   = specialPrefix + wildcardSegment
+)
+
+// attributeSegment is a segment of an attribute path. A quoted segment names
+// an attribute even if it reads like a wildcard or element segment.
+type attributeSegment struct {
+	name   string
+	quoted bool
+}
+
+// key returns the attribute map key of the segment.
+func (s attributeSegment) key(options *selectorOptions) string {
+	if !s.quoted && (s.name == wildcardSegment || isElementSegment(s.name, options)) {
+		return specialPrefix + s.name
+	}
+	return s.name
+}
+
+// mergeAttributesMaps returns the union of two attribute maps without
+// modifying either of them. An empty map stands for a whole attribute and
+// absorbs any sub-attributes.
+func mergeAttributesMaps(a map[string]interface{}, b map[string]interface{}) map[string]interface{} {
+	if len(a) == 0 || len(b) == 0 {
+		return map[string]interface{}{}
+	}
+	merged := make(map[string]interface{}, len(a)+len(b))
+	for field, value := range a {
+		merged[field] = value
+	}
+	for field, value := range b {
+		if existing, ok := merged[field]; !ok {
+			merged[field] = value
+		} else if segment, ok := existing.(*elementSegment); ok {
+			merged[field] = mergeElementSegments(segment, value.(*elementSegment))
+		} else {
+			merged[field] = mergeAttributesMaps(existing.(map[string]interface{}), value.(map[string]interface{}))
+		}
+	}
+	return merged
+}
+
+// fieldAttributes returns the sub-attributes of field, including those of a
+// wildcard segment, and whether field is listed at all.
+func fieldAttributes(attributesMap map[string]interface{}, field string) (map[string]interface{}, bool) {
+	newAttributesMap, listed := attributesMap[field].(map[string]interface{})
+	wildcardAttributesMap, wildcard := attributesMap[wildcardKey].(map[string]interface{})
+	switch {
+	case !wildcard:
+		return newAttributesMap, listed
+	case !listed:
+		return wildcardAttributesMap, true
+	}
+	return mergeAttributesMaps(newAttributesMap, wildcardAttributesMap), true
+}
+
+// attributeFields returns the fields to visit in object: the listed ones, or
+// all fields of object if a wildcard segment is listed.
+func attributeFields(attributesMap map[string]interface{}, object map[string]interface{}) map[string]interface{} {
+	if _, wildcard := attributesMap[wildcardKey]; wildcard {
+		return object
+	}
+	return attributesMap
+}
+
+func selectFieldsRecursively(attributesMap map[string]interface{}, object interface{}, options *selectorOptions) interface{} {
 	if len(attributesMap) == 0 {
+		if isJSONNull(object) {
+			return nil
+		}
 		return object
 	}
 	switch o := object.(type) {
 	case map[string]interface{}:
 		resultMap := make(map[string]interface{})
-		for field := range attributesMap {
-			newAttributesMap := attributesMap[field].(map[string]interface{})
+		for field := range attributeFields(attributesMap, o) {
+			newAttributesMap, _ := fieldAttributes(attributesMap, field)
 			if value, ok := o[field]; ok {
-				returnedValue := selectFieldsRecursively(newAttributesMap, value)
+				returnedValue := selectFieldsRecursively(newAttributesMap, value, options)
 				if returnedValue != nil {
 					resultMap[field] = returnedValue
+				} else if options.preserveNull && isJSONNull(value) {
+					resultMap[field] = nil
 				}
 			}
 		}
-		if len(resultMap) > 0 {
+		if len(resultMap) > 0 || options.preserveEmpty {
 			return resultMap
 		}
 		return nil
 	case []interface{}:
 		var returnedValues []interface{}
-		for _, item := range o {
-			value := selectFieldsRecursively(attributesMap, item)
+		elements := newArrayAttributes(attributesMap)
+		for i, item := range o {
+			var value interface{}
+			itemAttributesMap, whole := attributesMap, false
+			if len(elements.segments) > 0 {
+				itemAttributesMap, whole = elements.forElement(i, item)
+			}
+			switch {
+			case whole:
+				value = selectFieldsRecursively(nil, item, options)
+			case len(itemAttributesMap) > 0:
+				value = selectFieldsRecursively(itemAttributesMap, item, options)
+				// Items without any of the selected attributes are
+				// dropped, even though PreserveEmpty keeps empty objects.
+				if m, ok := value.(map[string]interface{}); ok && len(m) == 0 {
+					value = nil
+				}
+			}
 			if value != nil {
 				returnedValues = append(returnedValues, value)
 			}
@@ -35,20 +142,25 @@ func selectFieldsRecursively(attributesMap map[string]interface{}, object interf
 		if len(returnedValues) > 0 {
 			return returnedValues
 		}
+		if options.preserveEmpty {
+			return []interface{}{}
+		}
 		return nil
 	}
+	if generic, ok := genericValue(object); ok && generic != nil {
+		return selectFieldsRecursively(attributesMap, generic, options)
+	}
 	return nil
 }
 
-func excludeFieldsRecursively(attributesMap map[string]interface{}, object interface{}) {
-	//TODO: create new object instead of modifying the existing one
+func excludeFieldsInPlaceRecursively(attributesMap map[string]interface{}, object interface{}) {
 	if len(attributesMap) == 0 {
 		return
 	}
 	switch o := object.(type) {
 	case map[string]interface{}:
-		for field := range attributesMap {
-			newAttributesMap := attributesMap[field].(map[string]interface{})
+		for field := range attributeFields(attributesMap, o) {
+			newAttributesMap, _ := fieldAttributes(attributesMap, field)
 			value, ok := o[field]
 			if !ok {
 				continue
@@ -58,44 +170,172 @@ func excludeFieldsRecursively(attributesMap map[string]interface{}, object inter
 				continue
 			}
 			if value != nil {
-				excludeFieldsRecursively(newAttributesMap, value)
+				excludeFieldsInPlaceRecursively(newAttributesMap, value)
 			}
 		}
 	case []interface{}:
 		for _, item := range o {
-			excludeFieldsRecursively(attributesMap, item)
+			excludeFieldsInPlaceRecursively(attributesMap, item)
 		}
+	default:
+		excludeFieldsInPlaceValue(attributesMap, reflect.ValueOf(object))
 	}
 }
 
-func createAttributesMap(attributesList []string) map[string]interface{} {
+// Attributes listed in includeMap are kept even if attributesMap excludes them.
+func excludeFieldsRecursively(attributesMap map[string]interface{}, includeMap map[string]interface{}, object interface{}, options *selectorOptions) interface{} {
+	switch o := object.(type) {
+	case map[string]interface{}:
+		resultMap := make(map[string]interface{}, len(o))
+		for field, value := range o {
+			newAttributesMap, excluded := fieldAttributes(attributesMap, field)
+			newIncludeMap, included := fieldAttributes(includeMap, field)
+			switch {
+			case !excluded || included && len(newIncludeMap) == 0:
+				resultMap[field] = value
+			case len(newAttributesMap) == 0:
+				if included {
+					if returnedValue := selectFieldsRecursively(newIncludeMap, value, options); returnedValue != nil {
+						resultMap[field] = returnedValue
+					} else if options.preserveNull && isJSONNull(value) {
+						resultMap[field] = nil
+					}
+				}
+			default:
+				resultMap[field] = excludeFieldsRecursively(newAttributesMap, newIncludeMap, value, options)
+			}
+		}
+		return resultMap
+	case []interface{}:
+		elements := newArrayAttributes(attributesMap)
+		includedElements := newArrayAttributes(includeMap)
+		if len(elements.segments) > 0 || len(includedElements.segments) > 0 {
+			return excludeElementFields(elements, includedElements, o, options)
+		}
+		resultList := make([]interface{}, len(o))
+		for i, item := range o {
+			resultList[i] = excludeFieldsRecursively(attributesMap, includeMap, item, options)
+		}
+		return resultList
+	}
+	if generic, ok := genericValue(object); ok {
+		return excludeFieldsRecursively(attributesMap, includeMap, generic, options)
+	}
+	return object
+}
+
+func parseAttributePath(path string, options *selectorOptions) ([]attributeSegment, error) {
+	if path == "" {
+		return nil, &PathError{path, 0, "empty path"}
+	}
+	parts := splitUnquoted(path, '/', options.elementSegments())
+	segments := make([]attributeSegment, len(parts))
+	for i, part := range parts {
+		if part == "" {
+			return nil, &PathError{path, i, "empty segment"}
+		}
+		name, err := unquote(part)
+		if err != nil {
+			return nil, &PathError{path, i, err.Error()}
+		}
+		segments[i] = attributeSegment{name, strings.HasPrefix(part, "'")}
+	}
+	return segments, nil
+}
+
+func splitAttributePath(path string, options *selectorOptions) ([]string, error) {
+	segments, err := parseAttributePath(path, options)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(segments))
+	for i, segment := range segments {
+		names[i] = segment.name
+	}
+	return names, nil
+}
+
+// createAttributesMap skips malformed paths and reports the first of them.
+func createAttributesMap(attributesList []string, options *selectorOptions) (map[string]interface{}, error) {
+	var firstErr error
 	attributesMap := make(map[string]interface{})
 	for _, attributes := range attributesList {
-		currentLayerMap := &attributesMap
-		for _, attribute := range strings.Split(attributes, "/") {
-			if _, ok := (*currentLayerMap)[attribute]; !ok {
-				(*currentLayerMap)[attribute] = make(map[string]interface{})
+		segments, err := parseAttributePath(attributes, options)
+		var elements []*elementSegment
+		if err == nil {
+			elements, err = parseElementSegments(attributes, segments, options)
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
 			}
-			object, _ := (*currentLayerMap)[attribute].(map[string]interface{})
-			currentLayerMap = &object
+			continue
+		}
+		// A whole attribute, i.e. an empty map, absorbs its sub-attributes
+		// whichever of them is listed first.
+		currentLayerMap := attributesMap
+		for i, segment := range segments {
+			attribute := segment.key(options)
+			if elements[i] != nil {
+				element, ok := currentLayerMap[attribute].(*elementSegment)
+				if !ok {
+					element = elements[i]
+					element.attributesMap = make(map[string]interface{})
+					currentLayerMap[attribute] = element
+				} else if len(element.attributesMap) == 0 {
+					break
+				}
+				if i == len(segments)-1 {
+					element.attributesMap = make(map[string]interface{})
+				}
+				currentLayerMap = element.attributesMap
+				continue
+			}
+			if i == len(segments)-1 {
+				currentLayerMap[attribute] = make(map[string]interface{})
+				break
+			}
+			object, ok := currentLayerMap[attribute].(map[string]interface{})
+			if ok && len(object) == 0 {
+				break
+			}
+			if !ok {
+				object = make(map[string]interface{})
+				currentLayerMap[attribute] = object
+			}
+			currentLayerMap = object
 		}
 	}
-	return attributesMap
+	return attributesMap, firstErr
 }
 
-func SelectFields(attributesList []string, data interface{}) interface{} {
-	attributesMap := createAttributesMap(attributesList)
-	if len(attributesMap) > 0 && data != nil {
-		modifiedData := selectFieldsRecursively(attributesMap, data)
-		return modifiedData
-	}
-	return data
+// SelectFields returns the listed attributes of data. Malformed attribute
+// paths are ignored, use CompileSelect to detect them.
+func SelectFields(attributesList []string, data interface{}, options ...SelectorOption) interface{} {
+	s := newSelector(options)
+	s.fields = attributesList
+	s.compile()
+	return s.Apply(data)
+}
+
+// ExcludeFields returns a copy of data without the listed attributes. Parts
+// of data that are not affected by the exclusion are shared with the copy,
+// data itself is never modified. Malformed attribute paths are ignored, use
+// CompileExclude to detect them.
+func ExcludeFields(attributesList []string, data interface{}, options ...SelectorOption) interface{} {
+	s := newSelector(options)
+	s.excludeFields = attributesList
+	s.compile()
+	return s.Apply(data)
 }
 
-func ExcludeFields(attributesList []string, data interface{}) interface{} {
-	attributesMap := createAttributesMap(attributesList)
+// ExcludeFieldsInPlace removes the listed attributes from data itself and
+// returns it. It is meant for callers that own data exclusively. Attributes
+// backed by struct fields cannot be removed and are left in place.
+func ExcludeFieldsInPlace(attributesList []string, data interface{}) interface{} {
+	attributesMap, _ := createAttributesMap(attributesList, &selectorOptions{})
 	if len(attributesMap) > 0 && data != nil {
-		excludeFieldsRecursively(attributesMap, data)
+		excludeFieldsInPlaceRecursively(attributesMap, data)
 	}
 	return data
 }
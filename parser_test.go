@@ -526,6 +526,84 @@ func TestSelectNullInput(t *testing.T) {
 	assert.JSONEq(t, expectedOutput, string(res))
 }
 
+func TestSelectNullAttribute(t *testing.T) {
+	input := `
+	[
+	{"id":123, "vnfInstanceName":null, "parts":[{"id":1, "color":null}, {"id":2, "color":"green"}]},
+	{"id":456, "vnfInstanceName":"web", "parts":null}
+	]
+	`
+	attributes := []string{"vnfInstanceName", "parts/color"}
+	expectedOutput := `
+	[
+	{"parts":[{"color":"green"}]},
+	{"vnfInstanceName":"web"}
+	]
+	`
+	var payload interface{}
+	err := json.Unmarshal([]byte(input), &payload)
+
+	assert.Nil(t, err)
+
+	modifiedPayload := SelectFields(attributes, payload)
+
+	res, err := json.MarshalIndent(modifiedPayload, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(res))
+	assert.JSONEq(t, expectedOutput, string(res))
+}
+
+func TestSelectNullAttributePreserved(t *testing.T) {
+	input := `
+	[
+	{"id":123, "vnfInstanceName":null, "parts":[{"id":1, "color":null}, {"id":2, "color":"green"}]},
+	{"id":456, "vnfInstanceName":"web", "parts":null},
+	{"id":789}
+	]
+	`
+	attributes := []string{"vnfInstanceName", "parts/color"}
+	expectedOutput := `
+	[
+	{"vnfInstanceName":null, "parts":[{"color":null}, {"color":"green"}]},
+	{"vnfInstanceName":"web", "parts":null}
+	]
+	`
+	var payload interface{}
+	err := json.Unmarshal([]byte(input), &payload)
+
+	assert.Nil(t, err)
+
+	modifiedPayload := SelectFields(attributes, payload, PreserveNull())
+
+	res, err := json.MarshalIndent(modifiedPayload, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(res))
+	assert.JSONEq(t, expectedOutput, string(res))
+}
+
+func TestSelectNullInputPreserved(t *testing.T) {
+	input := `null`
+	attributes := []string{"cores", "parts/id"}
+	expectedOutput := `null`
+	var payload interface{}
+	err := json.Unmarshal([]byte(input), &payload)
+
+	assert.Nil(t, err)
+
+	modifiedPayload := SelectFields(attributes, payload, PreserveNull())
+
+	res, err := json.MarshalIndent(modifiedPayload, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(res))
+	assert.JSONEq(t, expectedOutput, string(res))
+}
+
 func TestExcludeNullInput(t *testing.T) {
 	input := `null`
 	attributes := []string{"cores", "parts/id"}
@@ -564,6 +642,84 @@ func TestSelectEmptyMapInput(t *testing.T) {
 	assert.JSONEq(t, expectedOutput, string(res))
 }
 
+func TestSelectEmptyMapInputPreserved(t *testing.T) {
+	input := `{}`
+	attributes := []string{"cores", "parts/id"}
+	expectedOutput := `{}`
+	var payload interface{}
+	err := json.Unmarshal([]byte(input), &payload)
+
+	assert.Nil(t, err)
+
+	modifiedPayload := SelectFields(attributes, payload, PreserveEmpty())
+
+	res, err := json.MarshalIndent(modifiedPayload, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(res))
+	assert.JSONEq(t, expectedOutput, string(res))
+}
+
+func TestSelectEmptyContainersPreserved(t *testing.T) {
+	input := `
+	[
+	{"id":123, "parts":[], "spec":{}},
+	{"id":456, "parts":[{"id":1}, {"id":2, "color":"green"}], "spec":{"size":1}},
+	{"id":789, "parts":[{"id":3}, "broken"], "spec":"none"}
+	]
+	`
+	attributes := []string{"parts/color", "spec/cores"}
+	expectedOutput := `
+	[
+	{"parts":[], "spec":{}},
+	{"parts":[{"color":"green"}], "spec":{}},
+	{"parts":[]}
+	]
+	`
+	var payload interface{}
+	err := json.Unmarshal([]byte(input), &payload)
+
+	assert.Nil(t, err)
+
+	modifiedPayload := SelectFields(attributes, payload, PreserveEmpty())
+
+	res, err := json.MarshalIndent(modifiedPayload, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(res))
+	assert.JSONEq(t, expectedOutput, string(res))
+}
+
+func TestSelectEmptyContainersDropped(t *testing.T) {
+	input := `
+	[
+	{"id":123, "parts":[], "spec":{}},
+	{"id":456, "parts":[{"id":1}, {"id":2, "color":"green"}], "spec":{"size":1}}
+	]
+	`
+	attributes := []string{"parts/color", "spec/cores"}
+	expectedOutput := `
+	[
+	{"parts":[{"color":"green"}]}
+	]
+	`
+	var payload interface{}
+	err := json.Unmarshal([]byte(input), &payload)
+
+	assert.Nil(t, err)
+
+	modifiedPayload := SelectFields(attributes, payload)
+
+	res, err := json.MarshalIndent(modifiedPayload, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(res))
+	assert.JSONEq(t, expectedOutput, string(res))
+}
+
 func TestExcludeEmptyMapInput(t *testing.T) {
 	input := `{}`
 	attributes := []string{"cores", "parts/id"}
@@ -792,3 +948,310 @@ func TestExcludeEmptyAttributes(t *testing.T) {
 	fmt.Println(string(res))
 	assert.JSONEq(t, expectedOutput, string(res))
 }
+
+func TestExcludeDoesNotModifyInput(t *testing.T) {
+	input := `
+	[
+	{"id":123, "weight":100, "parts":[{"id":1, "color":"red"}, {"id":2, "color":"green"}]},
+	{"id":456, "weight":500, "parts":[{"id":3, "color":"green"}, {"id":4, "color":"blue"}]}
+	]
+	`
+	attributes := []string{"weight", "parts/color"}
+	expectedOutput := `
+	[
+	{"id":123, "parts":[{"id":1}, {"id":2}]},
+	{"id":456, "parts":[{"id":3}, {"id":4}]}
+	]
+	`
+	var payload interface{}
+	err := json.Unmarshal([]byte(input), &payload)
+
+	assert.Nil(t, err)
+
+	modifiedPayload := ExcludeFields(attributes, payload)
+
+	res, err := json.MarshalIndent(modifiedPayload, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(res))
+	assert.JSONEq(t, expectedOutput, string(res))
+
+	res, err = json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	assert.JSONEq(t, input, string(res))
+}
+
+func TestExcludeInPlace(t *testing.T) {
+	input := `
+	[
+	{"id":123, "weight":100, "parts":[{"id":1, "color":"red"}, {"id":2, "color":"green"}]},
+	{"id":456, "weight":500, "parts":[{"id":3, "color":"green"}, {"id":4, "color":"blue"}]}
+	]
+	`
+	attributes := []string{"weight", "parts/color"}
+	expectedOutput := `
+	[
+	{"id":123, "parts":[{"id":1}, {"id":2}]},
+	{"id":456, "parts":[{"id":3}, {"id":4}]}
+	]
+	`
+	var payload interface{}
+	err := json.Unmarshal([]byte(input), &payload)
+
+	assert.Nil(t, err)
+
+	modifiedPayload := ExcludeFieldsInPlace(attributes, payload)
+
+	res, err := json.MarshalIndent(modifiedPayload, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(res))
+	assert.JSONEq(t, expectedOutput, string(res))
+
+	res, err = json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	assert.JSONEq(t, expectedOutput, string(res))
+}
+
+func TestSelectMalformedAttributes(t *testing.T) {
+	input := `
+	[
+	{"id":123, "weight":100, "parts":[{"id":1, "color":"red"}, {"id":2, "color":"green"}]},
+	{"id":456, "weight":500, "parts":[{"id":3, "color":"green"}, {"id":4, "color":"blue"}]}
+	]
+	`
+	attributes := []string{"parts//color", "", "id"}
+	expectedOutput := `
+	[
+	{"id":123},
+	{"id":456}
+	]
+	`
+	var payload interface{}
+	err := json.Unmarshal([]byte(input), &payload)
+
+	assert.Nil(t, err)
+
+	modifiedPayload := SelectFields(attributes, payload)
+
+	res, err := json.MarshalIndent(modifiedPayload, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(res))
+	assert.JSONEq(t, expectedOutput, string(res))
+}
+
+func TestSelectConcreteContainerTypes(t *testing.T) {
+	payload := map[string]interface{}{
+		"id": 123,
+		"parts": []map[string]interface{}{
+			{"id": 1, "color": "red"},
+			{"id": 2, "color": "green"},
+		},
+		"metadata": map[string]string{"owner": "alice", "site": "athens"},
+		"tags":     []string{"web", "public"},
+	}
+	attributes := []string{"parts/color", "metadata/site", "tags"}
+	expectedOutput := `
+	{"parts":[{"color":"red"}, {"color":"green"}], "metadata":{"site":"athens"}, "tags":["web", "public"]}
+	`
+	modifiedPayload := SelectFields(attributes, payload)
+
+	res, err := json.MarshalIndent(modifiedPayload, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(res))
+	assert.JSONEq(t, expectedOutput, string(res))
+}
+
+func TestExcludeConcreteContainerTypes(t *testing.T) {
+	payload := map[string]interface{}{
+		"id": 123,
+		"parts": []map[string]interface{}{
+			{"id": 1, "color": "red"},
+			{"id": 2, "color": "green"},
+		},
+		"metadata": map[string]string{"owner": "alice", "site": "athens"},
+	}
+	attributes := []string{"parts/color", "metadata/site"}
+	expectedOutput := `
+	{"id":123, "parts":[{"id":1}, {"id":2}], "metadata":{"owner":"alice"}}
+	`
+	modifiedPayload := ExcludeFields(attributes, payload)
+
+	res, err := json.MarshalIndent(modifiedPayload, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(res))
+	assert.JSONEq(t, expectedOutput, string(res))
+	assert.Equal(t, "green", payload["parts"].([]map[string]interface{})[1]["color"])
+	assert.Equal(t, "athens", payload["metadata"].(map[string]string)["site"])
+}
+
+type testSite string
+
+func TestExcludeInPlaceConcreteContainerTypes(t *testing.T) {
+	payload := map[string]interface{}{
+		"id": 123,
+		"parts": []map[string]interface{}{
+			{"id": 1, "color": "red"},
+			{"id": 2, "color": "green"},
+		},
+		"metadata": map[testSite]string{"owner": "alice", "site": "athens"},
+		"nested":   &map[string][]map[string]int{"list": {{"a": 1, "b": 2}}},
+	}
+	attributes := []string{"parts/color", "metadata/site", "nested/list/b"}
+	expectedOutput := `
+	{"id":123, "parts":[{"id":1}, {"id":2}], "metadata":{"owner":"alice"}, "nested":{"list":[{"a":1}]}}
+	`
+	ExcludeFieldsInPlace(attributes, payload)
+
+	res, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(res))
+	assert.JSONEq(t, expectedOutput, string(res))
+}
+
+func TestSelectWildcardAttribute(t *testing.T) {
+	input := `
+	{"id":"123", "extensions":{
+	 "monitoring":{"version":"1.2", "endpoint":"http://mon"},
+	 "backup":{"version":"0.9", "schedule":"daily"},
+	 "note":"free text"
+	}}
+	`
+	attributes := []string{"extensions/*/version", "extensions/backup/schedule"}
+	expectedOutput := `
+	{"extensions":{
+	 "monitoring":{"version":"1.2"},
+	 "backup":{"version":"0.9", "schedule":"daily"}
+	}}
+	`
+	var payload interface{}
+	err := json.Unmarshal([]byte(input), &payload)
+
+	assert.Nil(t, err)
+
+	modifiedPayload := SelectFields(attributes, payload)
+
+	res, err := json.MarshalIndent(modifiedPayload, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(res))
+	assert.JSONEq(t, expectedOutput, string(res))
+}
+
+func TestExcludeWildcardAttribute(t *testing.T) {
+	input := `
+	{"id":"123", "extensions":{
+	 "monitoring":{"version":"1.2", "endpoint":"http://mon"},
+	 "backup":{"version":"0.9", "schedule":"daily"},
+	 "note":"free text"
+	}}
+	`
+	attributes := []string{"extensions/*/version", "extensions/backup/schedule"}
+	expectedOutput := `
+	{"id":"123", "extensions":{
+	 "monitoring":{"endpoint":"http://mon"},
+	 "backup":{},
+	 "note":"free text"
+	}}
+	`
+	var payload interface{}
+	err := json.Unmarshal([]byte(input), &payload)
+
+	assert.Nil(t, err)
+
+	modifiedPayload := ExcludeFields(attributes, payload)
+
+	res, err := json.MarshalIndent(modifiedPayload, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(res))
+	assert.JSONEq(t, expectedOutput, string(res))
+
+	ExcludeFieldsInPlace(attributes, payload)
+
+	res, err = json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	assert.JSONEq(t, expectedOutput, string(res))
+}
+
+func TestWildcardOnConcreteContainerTypes(t *testing.T) {
+	payload := map[string]interface{}{
+		"id": "123",
+		"vnfConfigurableProperties": map[string]map[string]string{
+			"isAutoscaleEnabled": {"value": "true", "source": "vnfd"},
+			"isAutohealEnabled":  {"value": "false", "source": "user"},
+		},
+	}
+	attributes := []string{"vnfConfigurableProperties/*/source"}
+
+	res, err := json.Marshal(SelectFields(attributes, payload))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"vnfConfigurableProperties":{"isAutoscaleEnabled":{"source":"vnfd"}, "isAutohealEnabled":{"source":"user"}}}`, string(res))
+
+	ExcludeFieldsInPlace(attributes, payload)
+
+	res, err = json.Marshal(payload)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"id":"123", "vnfConfigurableProperties":{"isAutoscaleEnabled":{"value":"true"}, "isAutohealEnabled":{"value":"false"}}}`, string(res))
+}
+
+func TestWildcardWholeAttributes(t *testing.T) {
+	input := `{"id":"123", "metadata":{"owner":"alice", "site":"athens"}}`
+	var payload interface{}
+	err := json.Unmarshal([]byte(input), &payload)
+
+	assert.Nil(t, err)
+
+	res, err := json.Marshal(SelectFields([]string{"metadata/*"}, payload))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"metadata":{"owner":"alice", "site":"athens"}}`, string(res))
+
+	res, err = json.Marshal(ExcludeFields([]string{"*"}, payload))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{}`, string(res))
+}
+
+func TestSelectOnlyMalformedAttributes(t *testing.T) {
+	input := `{"id":123, "parts":[{"id":1, "color":"red"}]}`
+	var payload interface{}
+	err := json.Unmarshal([]byte(input), &payload)
+
+	assert.Nil(t, err)
+	assert.Nil(t, SelectFields([]string{"a//b", ""}, payload))
+	assert.Nil(t, SelectFields([]string{"a//b"}, []interface{}{payload}))
+	assert.Equal(t, payload, SelectFields(nil, payload))
+
+	s, err := CompileSelect([]string{"a//b"})
+	assert.Nil(t, s)
+	assert.NotNil(t, err)
+}
+
+func TestSelectEmptyItemsDroppedWithPreserveEmpty(t *testing.T) {
+	var payload interface{}
+	err := json.Unmarshal([]byte(`{"parts":[{"id":1}, {"id":2}], "spec":[{}]}`), &payload)
+
+	assert.Nil(t, err)
+
+	res, err := json.Marshal(SelectFields([]string{"parts/color", "spec"}, payload, PreserveEmpty()))
+
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"parts":[], "spec":[{}]}`, string(res))
+}
@@ -0,0 +1,18 @@
+package etsiparser
+
+import "strings"
+
+// Predicate segments are a non-standard extension of the SOL013 attribute
+// paths, enabled by the ArrayPredicates option. A segment "[filter]" holding
+// an attribute-based filter addresses the elements of an array the filter
+// matches, e.g. "vnfcResourceInfo/[(eq,vduId,VDU1)]/computeResource". The
+// attributes of the filter are relative to the element and its expressions
+// follow the semantics of Filter.Match.
+
+func isPredicateSegment(segment string) bool {
+	return strings.HasPrefix(segment, "[(") && strings.HasSuffix(segment, ")]")
+}
+
+func parsePredicateSegment(segment string) (Filter, error) {
+	return ParseFilter(segment[1 : len(segment)-1])
+}
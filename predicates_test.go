@@ -0,0 +1,143 @@
+package etsiparser
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectArrayPredicate(t *testing.T) {
+	attributes := []string{"id", "vnfcResourceInfo/[(eq,vduId,VDU1)]/computeResource"}
+	expectedOutput := `
+	{"id":"123", "vnfcResourceInfo":[
+	 {"computeResource":{"resourceId":"vm1"}},
+	 {"computeResource":{"resourceId":"vm2"}}
+	]}
+	`
+	modifiedPayload := SelectFields(attributes, unmarshalPayload(t, vnfcResourcesInput), ArrayPredicates())
+
+	assert.JSONEq(t, expectedOutput, marshalPayload(modifiedPayload))
+}
+
+func TestSelectArrayPredicateWholeElements(t *testing.T) {
+	attributes := []string{"vnfcResourceInfo/[(eq,computeResource/resourceId,vm2,vm3)]", "vnfcResourceInfo/id"}
+	expectedOutput := `
+	{"vnfcResourceInfo":[
+	 {"id":"vnfc1"},
+	 {"id":"vnfc2", "vduId":"VDU1", "computeResource":{"resourceId":"vm2"}},
+	 {"id":"vnfc3", "vduId":"VDU2", "computeResource":{"resourceId":"vm3"}},
+	 {"id":"vnfc4"}
+	]}
+	`
+	modifiedPayload := SelectFields(attributes, unmarshalPayload(t, vnfcResourcesInput), ArrayPredicates())
+
+	assert.JSONEq(t, expectedOutput, marshalPayload(modifiedPayload))
+}
+
+func TestSelectArrayPredicateWithIndexes(t *testing.T) {
+	attributes := []string{"vnfcResourceInfo/[(neq,vduId,VDU1);(cont,id,4)]/id", "vnfcResourceInfo/[0]/id"}
+	expectedOutput := `{"vnfcResourceInfo":[{"id":"vnfc1"}, {"id":"vnfc4"}]}`
+
+	modifiedPayload := SelectFields(attributes, unmarshalPayload(t, vnfcResourcesInput), ArrayIndexes(), ArrayPredicates())
+
+	assert.JSONEq(t, expectedOutput, marshalPayload(modifiedPayload))
+}
+
+func TestSelectArrayPredicateStructs(t *testing.T) {
+	attributes := []string{"id", "instantiatedVnfInfo/vnfcResourceInfo/[(eq,vduId,VDU2)]"}
+	expectedOutput := `
+	[
+	{"id":"123", "instantiatedVnfInfo":{"vnfcResourceInfo":[{"id":"vnfc2", "vduId":"VDU2"}]}},
+	{"id":"456"}
+	]
+	`
+	modifiedPayload := SelectFields(attributes, testVnfInstances(), ArrayPredicates())
+
+	assert.JSONEq(t, expectedOutput, marshalPayload(modifiedPayload))
+}
+
+func TestSelectArrayPredicateWithoutOption(t *testing.T) {
+	attributes := []string{"id", "vnfcResourceInfo/[(eq,vduId,VDU1)]/computeResource"}
+
+	modifiedPayload := SelectFields(attributes, unmarshalPayload(t, vnfcResourcesInput))
+
+	assert.JSONEq(t, `{"id":"123"}`, marshalPayload(modifiedPayload))
+}
+
+func TestExcludeArrayPredicate(t *testing.T) {
+	attributes := []string{"vnfcResourceInfo/[(eq,vduId,VDU2)]", "vnfcResourceInfo/[(eq,id,vnfc1)]/computeResource"}
+	expectedOutput := `
+	{"id":"123", "vnfcResourceInfo":[
+	 {"id":"vnfc1", "vduId":"VDU1"},
+	 {"id":"vnfc2", "vduId":"VDU1", "computeResource":{"resourceId":"vm2"}}
+	]}
+	`
+	modifiedPayload := ExcludeFields(attributes, unmarshalPayload(t, vnfcResourcesInput), ArrayPredicates())
+
+	assert.JSONEq(t, expectedOutput, marshalPayload(modifiedPayload))
+}
+
+func TestParseSelectorArrayPredicate(t *testing.T) {
+	query, _ := url.ParseQuery("fields=id,vnfcResourceInfo/[(eq,vduId,VDU2)]/id")
+	s, err := ParseSelector(query, ArrayPredicates())
+	assert.Nil(t, err)
+
+	assert.JSONEq(t, `{"id":"123", "vnfcResourceInfo":[{"id":"vnfc3"}, {"id":"vnfc4"}]}`, marshalPayload(s.Apply(unmarshalPayload(t, vnfcResourcesInput))))
+}
+
+func TestCompileInvalidArrayPredicate(t *testing.T) {
+	_, err := CompileSelect([]string{"vnfcResourceInfo/[(like,vduId,VDU1)]/id"}, ArrayPredicates())
+
+	assert.Equal(t, &PathError{"vnfcResourceInfo/[(like,vduId,VDU1)]/id", 1, `invalid predicate: unknown operator "like"`}, err)
+
+	_, err = CompileSelect([]string{"vnfcResourceInfo/[(like,vduId,VDU1)]/id"})
+
+	assert.Nil(t, err)
+}
+
+func TestCompileParsesElementSegmentsOnce(t *testing.T) {
+	s, err := CompileSelect([]string{"vnfcResourceInfo/[(eq,vduId,VDU1)]/id", "vnfcResourceInfo/[(eq,vduId,VDU1)]/vduId", "vnfcResourceInfo/[1:]"}, ArrayIndexes(), ArrayPredicates())
+	assert.Nil(t, err)
+
+	vnfcResourceInfo := s.selectMap["vnfcResourceInfo"].(map[string]interface{})
+	predicate, ok := vnfcResourceInfo[specialPrefix+"[(eq,vduId,VDU1)]"].(*elementSegment)
+	if assert.True(t, ok) {
+		assert.Equal(t, &Filter{Expressions: []Expression{{Operator: OperatorEqual, Attribute: []string{"vduId"}, Values: []string{"VDU1"}}}}, predicate.predicate)
+		assert.Equal(t, map[string]interface{}{"id": map[string]interface{}{}, "vduId": map[string]interface{}{}}, predicate.attributesMap)
+	}
+	indexes, ok := vnfcResourceInfo[specialPrefix+"[1:]"].(*elementSegment)
+	if assert.True(t, ok) {
+		assert.Equal(t, indexRange{1, -1}, indexes.indexes)
+	}
+	expectedOutput := `
+	{"vnfcResourceInfo":[
+	 {"id":"vnfc1", "vduId":"VDU1"},
+	 {"id":"vnfc2", "vduId":"VDU1", "computeResource":{"resourceId":"vm2"}},
+	 {"id":"vnfc3", "vduId":"VDU2", "computeResource":{"resourceId":"vm3"}},
+	 {"id":"vnfc4", "vduId":"VDU2", "computeResource":{"resourceId":"vm4"}}
+	]}
+	`
+	assert.JSONEq(t, expectedOutput, marshalPayload(s.Apply(unmarshalPayload(t, vnfcResourcesInput))))
+}
+
+func BenchmarkSelectArrayPredicate(b *testing.B) {
+	instances := testVnfInstances()
+	s, err := CompileSelect([]string{"id", "instantiatedVnfInfo/vnfcResourceInfo/[(eq,vduId,VDU2)]/id"}, ArrayPredicates())
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Apply(instances)
+	}
+}
+
+func TestSelectMergedElementSegments(t *testing.T) {
+	input := `{"extensions":{"a":{"items":[{"x":1, "y":2, "z":3}, {"x":4, "y":5, "z":6}]}}}`
+	attributes := []string{"extensions/*/items/[0]/x", "extensions/a/items/[0]/y"}
+
+	modifiedPayload := SelectFields(attributes, unmarshalPayload(t, input), ArrayIndexes())
+
+	assert.JSONEq(t, `{"extensions":{"a":{"items":[{"x":1, "y":2}]}}}`, marshalPayload(modifiedPayload))
+}
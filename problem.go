@@ -0,0 +1,64 @@
+package etsiparser
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// ProblemDetailsContentType is the media type of a ProblemDetails body.
+const ProblemDetailsContentType = "application/problem+json"
+
+// ProblemDetails is the error response body defined in ETSI GS NFV-SOL 013
+// clause 6.4 and IETF RFC 7807.
+type ProblemDetails struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// NewProblemDetails converts an error returned by ParseSelector,
+// ParseResourceSelector, CompileSelect, CompileExclude or ParseFilter into a
+// "400 Bad Request" ProblemDetails. Any other error is reported as "500
+// Internal Server Error" without exposing its message. instance is the URI
+// of the request, it may be empty.
+func NewProblemDetails(err error, instance string) *ProblemDetails {
+	var (
+		pathError     *PathError
+		conflictError *SelectorConflictError
+		filterError   *FilterSyntaxError
+	)
+	p := &ProblemDetails{
+		Status:   http.StatusBadRequest,
+		Detail:   err.Error(),
+		Instance: instance,
+	}
+	switch {
+	case errors.As(err, &pathError):
+		p.Title = "Invalid attribute selector"
+	case errors.As(err, &conflictError):
+		p.Title = "Conflicting attribute selector parameters"
+	case errors.As(err, &filterError):
+		p.Title = "Invalid attribute-based filter"
+	default:
+		p.Status = http.StatusInternalServerError
+		p.Title = http.StatusText(http.StatusInternalServerError)
+		p.Detail = "The request could not be processed."
+	}
+	return p
+}
+
+// WriteProblemDetails writes p as the response, using p.Status as the status
+// code.
+func WriteProblemDetails(w http.ResponseWriter, p *ProblemDetails) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", ProblemDetailsContentType)
+	w.WriteHeader(p.Status)
+	_, err = w.Write(body)
+	return err
+}
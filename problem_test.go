@@ -0,0 +1,65 @@
+package etsiparser
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblemDetailsFromSelectorConflict(t *testing.T) {
+	query, _ := url.ParseQuery("all_fields&fields=id")
+	_, err := ParseSelector(query)
+	p := NewProblemDetails(err, "/vnflcm/v1/vnf_instances?all_fields&fields=id")
+
+	assert.Equal(t, &ProblemDetails{
+		Title:    "Conflicting attribute selector parameters",
+		Status:   http.StatusBadRequest,
+		Detail:   `parameter "all_fields" cannot be combined with "fields"`,
+		Instance: "/vnflcm/v1/vnf_instances?all_fields&fields=id",
+	}, p)
+}
+
+func TestProblemDetailsFromPathError(t *testing.T) {
+	_, err := CompileSelect([]string{"a//b"})
+	p := NewProblemDetails(err, "")
+
+	assert.Equal(t, &ProblemDetails{
+		Title:  "Invalid attribute selector",
+		Status: http.StatusBadRequest,
+		Detail: `invalid attribute path "a//b" at segment 1: empty segment`,
+	}, p)
+}
+
+func TestProblemDetailsFromFilterError(t *testing.T) {
+	_, err := ParseFilter("(like,vnfdId,abc)")
+	p := NewProblemDetails(err, "")
+
+	assert.Equal(t, http.StatusBadRequest, p.Status)
+	assert.Equal(t, "Invalid attribute-based filter", p.Title)
+	assert.Equal(t, err.Error(), p.Detail)
+}
+
+func TestProblemDetailsFromOtherError(t *testing.T) {
+	p := NewProblemDetails(errors.New("database password is hunter2"), "")
+
+	assert.Equal(t, http.StatusInternalServerError, p.Status)
+	assert.NotContains(t, p.Detail, "hunter2")
+}
+
+func TestWriteProblemDetails(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := WriteProblemDetails(w, &ProblemDetails{
+		Title:  "Invalid attribute selector",
+		Status: http.StatusBadRequest,
+		Detail: "bad path",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, ProblemDetailsContentType, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"title":"Invalid attribute selector", "status":400, "detail":"bad path"}`, w.Body.String())
+}
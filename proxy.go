@@ -0,0 +1,189 @@
+package etsiparser
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var proxiedParameters = []string{
+	AllFieldsParameter,
+	FieldsParameter,
+	ExcludeFieldsParameter,
+	ExcludeDefaultParameter,
+	FilterParameter,
+}
+
+type proxyQueryKey struct{}
+
+// proxyQuery holds the filter and selector of a proxied request.
+type proxyQuery struct {
+	filter   *Filter
+	selector *Selector
+}
+
+// Proxy is a reverse proxy that implements the attribute-based filter and
+// attribute selector parameters on behalf of upstreams that do not support
+// them. The parameters are removed from the forwarded request and applied
+// to the JSON body of the upstream response instead.
+type Proxy struct {
+	// Resource names the resource type registered in DefaultRegistry whose
+	// default-excluded and mandatory attributes apply. It may be empty.
+	Resource string
+	// ReverseProxy forwards the requests. Its ModifyResponse is set by
+	// NewProxy.
+	ReverseProxy *httputil.ReverseProxy
+}
+
+// NewProxy returns a proxy that forwards requests to target as
+// httputil.NewSingleHostReverseProxy does.
+func NewProxy(target *url.URL, resource string) *Proxy {
+	reverseProxy := httputil.NewSingleHostReverseProxy(target)
+	reverseProxy.ModifyResponse = modifyProxyResponse
+	return &Proxy{Resource: resource, ReverseProxy: reverseProxy}
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		p.ReverseProxy.ServeHTTP(w, r)
+		return
+	}
+	s, err := parseRequestSelector(p.Resource, r.URL.Query())
+	if err != nil {
+		WriteProblemDetails(w, NewProblemDetails(err, r.URL.RequestURI()))
+		return
+	}
+	q := &proxyQuery{selector: s}
+	if q.filter, err = ParseFilterQuery(r.URL.RawQuery); err != nil {
+		WriteProblemDetails(w, NewProblemDetails(err, r.URL.RequestURI()))
+		return
+	}
+	r = r.Clone(context.WithValue(r.Context(), proxyQueryKey{}, q))
+	r.URL.RawQuery = removeProxiedParameters(r.URL.RawQuery)
+	// Only identity and gzip responses can be transformed. Without an
+	// Accept-Encoding header the transport asks for gzip and decodes it.
+	if acceptsGzip(r.Header.Values("Accept-Encoding")) {
+		r.Header.Set("Accept-Encoding", "gzip")
+	} else {
+		r.Header.Del("Accept-Encoding")
+	}
+	p.ReverseProxy.ServeHTTP(w, r)
+}
+
+// acceptsGzip reports whether the Accept-Encoding header values allow a gzip
+// response.
+func acceptsGzip(values []string) bool {
+	for _, value := range values {
+		for _, coding := range strings.Split(value, ",") {
+			name, params := coding, ""
+			if i := strings.IndexByte(coding, ';'); i >= 0 {
+				name, params = coding[:i], coding[i+1:]
+			}
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name != "gzip" && name != "x-gzip" {
+				continue
+			}
+			q := strings.ReplaceAll(strings.ToLower(params), " ", "")
+			if !strings.HasPrefix(q, "q=") || strings.Trim(q[2:], "0.") != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// removeProxiedParameters returns the raw query without the proxied
+// parameters. The other parameters are kept as they are, including those
+// url.ParseQuery rejects.
+func removeProxiedParameters(rawQuery string) string {
+	var kept []string
+	for _, parameter := range strings.Split(rawQuery, "&") {
+		key := parameter
+		if i := strings.IndexByte(parameter, '='); i >= 0 {
+			key = parameter[:i]
+		}
+		if key, err := url.QueryUnescape(key); err == nil && isProxiedParameter(key) {
+			continue
+		}
+		if parameter != "" {
+			kept = append(kept, parameter)
+		}
+	}
+	return strings.Join(kept, "&")
+}
+
+func isProxiedParameter(key string) bool {
+	for _, parameter := range proxiedParameters {
+		if key == parameter {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *proxyQuery) apply(data interface{}) interface{} {
+	if items, ok := data.([]interface{}); ok && q.filter != nil {
+		matched := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			if q.filter.Match(item) {
+				matched = append(matched, item)
+			}
+		}
+		data = matched
+	}
+	return keepContainer(data, q.selector.Apply(data))
+}
+
+func modifyProxyResponse(resp *http.Response) error {
+	q, ok := resp.Request.Context().Value(proxyQueryKey{}).(*proxyQuery)
+	if !ok || resp.StatusCode < 200 || resp.StatusCode >= 300 ||
+		!isJSONContentType(resp.Header.Get("Content-Type")) ||
+		q.filter == nil && q.selector.changesNothing() {
+		return nil
+	}
+	encoding := resp.Header.Get("Content-Encoding")
+	if encoding != "" && encoding != "gzip" {
+		return nil
+	}
+
+	var reader io.Reader = resp.Body
+	if encoding == "gzip" {
+		gzipReader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+	original, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	body := transformJSON(original, q.apply)
+	if !bytes.Equal(body, original) {
+		resp.Header.Del("ETag")
+	}
+	if encoding == "gzip" {
+		var compressed bytes.Buffer
+		gzipWriter := gzip.NewWriter(&compressed)
+		if _, err := gzipWriter.Write(body); err != nil {
+			return err
+		}
+		if err := gzipWriter.Close(); err != nil {
+			return err
+		}
+		body = compressed.Bytes()
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return nil
+}
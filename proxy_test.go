@@ -0,0 +1,199 @@
+package etsiparser
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newUpstream(t *testing.T, upstream http.Handler) (*httptest.Server, *url.URL) {
+	server := httptest.NewServer(upstream)
+	target, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+	return server, target
+}
+
+func TestProxyFilterAndSelector(t *testing.T) {
+	var upstreamQuery url.Values
+	server, target := newUpstream(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamQuery = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(vnfInstancesInput))
+	}))
+	defer server.Close()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/vnf_instances?nextpage_opaque_marker=2&filter=(eq,vnfdId,abc)&fields=metadata", nil)
+	NewProxy(target, "VnfInstance").ServeHTTP(w, r)
+	expectedOutput := `
+	[
+	{"id":"123", "vnfdId":"abc", "instantiationState":"INSTANTIATED",
+	 "metadata":{"owner":"alice"}, "_links":{"self":{"href":"/vnf_instances/123"}}}
+	]
+	`
+	assert.Equal(t, url.Values{"nextpage_opaque_marker": {"2"}}, upstreamQuery)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, expectedOutput, w.Body.String())
+	assert.Equal(t, strconv.Itoa(w.Body.Len()), w.Header().Get("Content-Length"))
+	assert.Equal(t, "", w.Header().Get("ETag"))
+	assert.Equal(t, "/vnf_instances?nextpage_opaque_marker=2&filter=(eq,vnfdId,abc)&fields=metadata", r.URL.RequestURI())
+}
+
+func TestProxyGzipUpstream(t *testing.T) {
+	server, target := newUpstream(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var compressed bytes.Buffer
+		gzipWriter := gzip.NewWriter(&compressed)
+		gzipWriter.Write([]byte(vnfInstancesInput))
+		gzipWriter.Close()
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(compressed.Bytes())
+	}))
+	defer server.Close()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/vnf_instances?fields=id", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	NewProxy(target, "").ServeHTTP(w, r)
+
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, strconv.Itoa(w.Body.Len()), w.Header().Get("Content-Length"))
+	gzipReader, err := gzip.NewReader(w.Body)
+	assert.Nil(t, err)
+	body, err := io.ReadAll(gzipReader)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[{"id":"123"}, {"id":"456"}]`, string(body))
+}
+
+func TestProxyPassThrough(t *testing.T) {
+	server, target := newUpstream(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(vnfInstancesInput))
+	}))
+	defer server.Close()
+
+	w := httptest.NewRecorder()
+	NewProxy(target, "").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/vnf_instances?fields=id", nil))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, `"v1"`, w.Header().Get("ETag"))
+	assert.Equal(t, vnfInstancesInput, w.Body.String())
+}
+
+func TestProxyInvalidFilter(t *testing.T) {
+	called := false
+	server, target := newUpstream(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	w := httptest.NewRecorder()
+	NewProxy(target, "").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/vnf_instances?filter=(like,id,1)", nil))
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, ProblemDetailsContentType, w.Header().Get("Content-Type"))
+}
+
+func TestProxyFilterWithSemicolon(t *testing.T) {
+	server, target := newUpstream(t, jsonHandler(http.StatusOK, "application/json", vnfInstancesInput))
+	defer server.Close()
+
+	w := httptest.NewRecorder()
+	NewProxy(target, "").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/vnf_instances?filter=(neq,id,456);(eq,metadata/owner,'alice','bob')&fields=id", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"id":"123"}]`, w.Body.String())
+}
+
+func TestProxyKeepsOtherParameters(t *testing.T) {
+	var upstreamQuery string
+	server, target := newUpstream(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamQuery = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(vnfInstancesInput))
+	}))
+	defer server.Close()
+
+	w := httptest.NewRecorder()
+	NewProxy(target, "").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/x?b=2&a=1;2&fields=id&filter=(eq,id,123)&%66ilter=(eq,id,123)&c=%2F", nil))
+
+	assert.Equal(t, "b=2&a=1;2&c=%2F", upstreamQuery)
+	assert.JSONEq(t, `[{"id":"123"}]`, w.Body.String())
+}
+
+func TestProxyAcceptEncoding(t *testing.T) {
+	var acceptEncoding []string
+	server, target := newUpstream(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding = append(acceptEncoding, r.Header.Get("Accept-Encoding"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(vnfInstancesInput))
+	}))
+	defer server.Close()
+
+	for _, header := range []string{"br, deflate", "gzip;q=0, br", "br, gzip;q=0.5", ""} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/x?fields=id", nil)
+		if header != "" {
+			r.Header.Set("Accept-Encoding", header)
+		}
+		NewProxy(target, "").ServeHTTP(w, r)
+
+		assert.JSONEq(t, `[{"id":"123"}, {"id":"456"}]`, w.Body.String(), header)
+	}
+	assert.Equal(t, []string{"gzip", "gzip", "gzip", "gzip"}, acceptEncoding)
+	assert.True(t, acceptsGzip([]string{"br, gzip;q=0.5"}))
+	assert.False(t, acceptsGzip([]string{"br, deflate", "gzip;q=0"}))
+}
+
+func TestProxyKeepsEmptyContainers(t *testing.T) {
+	server, target := newUpstream(t, jsonHandler(http.StatusOK, "application/json", vnfInstancesInput))
+	defer server.Close()
+	objectServer, objectTarget := newUpstream(t, jsonHandler(http.StatusOK, "application/json", `{"id":"123"}`))
+	defer objectServer.Close()
+
+	requests := []struct {
+		target   *url.URL
+		resource string
+		uri      string
+		expected string
+	}{
+		{target, "VnfInstance", "/x?filter=(eq,id,nope)&fields=id", `[]`},
+		{target, "", "/x?filter=(eq,id,nope)&fields=id", `[]`},
+		{target, "", "/x?fields=nope", `[]`},
+		{objectTarget, "", "/x?fields=nope", `{}`},
+	}
+	for _, request := range requests {
+		w := httptest.NewRecorder()
+		NewProxy(request.target, request.resource).ServeHTTP(w, httptest.NewRequest(http.MethodGet, request.uri, nil))
+
+		assert.Equal(t, http.StatusOK, w.Code, request.uri)
+		assert.Equal(t, request.expected, w.Body.String(), request.uri)
+	}
+}
+
+func TestProxyKeepsUnchangedResponses(t *testing.T) {
+	body := `{"name":"<b>", "id":"123"}`
+	server, target := newUpstream(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	w := httptest.NewRecorder()
+	NewProxy(target, "").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/x?all_fields", nil))
+
+	assert.Equal(t, `"v1"`, w.Header().Get("ETag"))
+	assert.Equal(t, body, w.Body.String())
+}
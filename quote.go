@@ -0,0 +1,143 @@
+package etsiparser
+
+import (
+	"errors"
+	"strings"
+)
+
+// Attribute names that contain one of the characters with a meaning in
+// attribute paths, selector lists or filters, such as "/" or ",", are
+// enclosed in single quotes, following the SOL013 quoting of filter values.
+// A single quote within a quoted name is written as two single quotes, e.g.
+// the metadata key "site/rack" is addressed as "metadata/'site/rack'" and
+// "it's" as "'it''s'". A quoted segment always names an attribute, so "'*'"
+// addresses an attribute named "*" rather than all of them. Filter values
+// are quoted the same way, except that an unquoted value must not contain a
+// single quote at all.
+
+// Characters that require an attribute name or a filter value to be quoted.
+const (
+	attributeSpecials = "/,'();"
+	valueSpecials     = ",'();"
+)
+
+var errUnterminatedQuote = errors.New("unterminated quote")
+
+// isTokenStart reports whether s[i] starts a segment, a list item or a
+// filter term, where a quote or a bracket may open.
+func isTokenStart(s string, i int) bool {
+	return i == 0 || strings.IndexByte("/,(;", s[i-1]) >= 0
+}
+
+// skipQuoted returns the index just past the quoted string that starts at
+// s[i], or -1 if it is not terminated.
+func skipQuoted(s string, i int) int {
+	for j := i + 1; j < len(s); j++ {
+		if s[j] != '\'' {
+			continue
+		}
+		if j+1 < len(s) && s[j+1] == '\'' {
+			j++
+			continue
+		}
+		return j + 1
+	}
+	return -1
+}
+
+// indexUnquoted returns the index of the first c in s that is not quoted,
+// or -1. With brackets, c is not found within the brackets of an element
+// segment either.
+func indexUnquoted(s string, c byte, brackets bool) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\'' && isTokenStart(s, i):
+			end := skipQuoted(s, i)
+			if end < 0 {
+				return -1
+			}
+			i = end - 1
+		case s[i] == c && depth == 0:
+			return i
+		case brackets && s[i] == '[' && (depth > 0 || isTokenStart(s, i)):
+			depth++
+		case s[i] == ']' && depth > 0:
+			depth--
+		}
+	}
+	return -1
+}
+
+// splitUnquoted splits s at every sep that is not quoted. With brackets, it
+// keeps element segments such as "[(eq,vduId,VDU1)]" in one piece.
+func splitUnquoted(s string, sep byte, brackets bool) []string {
+	var parts []string
+	for {
+		i := indexUnquoted(s, sep, brackets)
+		if i < 0 {
+			return append(parts, s)
+		}
+		parts = append(parts, s[:i])
+		s = s[i+1:]
+	}
+}
+
+// unquote returns the name a path segment or filter value stands for.
+func unquote(s string) (string, error) {
+	if !strings.HasPrefix(s, "'") {
+		return s, nil
+	}
+	end := skipQuoted(s, 0)
+	switch {
+	case end < 0:
+		return "", errUnterminatedQuote
+	case end < len(s):
+		return "", errors.New("unexpected characters after quote")
+	}
+	return strings.ReplaceAll(s[1:end-1], "''", "'"), nil
+}
+
+// unquoteValue returns the filter value s stands for.
+func unquoteValue(s string) (string, error) {
+	if !strings.HasPrefix(s, "'") && strings.Contains(s, "'") {
+		return "", errors.New(`unquoted "'" in value`)
+	}
+	return unquote(s)
+}
+
+// QuoteAttribute returns the attribute name as a path segment, quoting it if
+// it contains characters with a meaning in attribute paths.
+func QuoteAttribute(name string) string {
+	if name != "" && !strings.ContainsAny(name, attributeSpecials) && name[0] != '[' && name != wildcardSegment {
+		return name
+	}
+	return "'" + strings.ReplaceAll(name, "'", "''") + "'"
+}
+
+// QuoteFilterValue returns the value as a filter value, quoting it if it
+// contains characters with a meaning in filters, e.g. "'a,b'" for "a,b".
+func QuoteFilterValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, valueSpecials) {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// JoinAttributePath returns the attribute path of the given attribute names,
+// e.g. "metadata/'site/rack'" for "metadata" and "site/rack". It can be used
+// in the fields and exclude_fields parameters and in filters.
+func JoinAttributePath(names ...string) string {
+	segments := make([]string, len(names))
+	for i, name := range names {
+		segments[i] = QuoteAttribute(name)
+	}
+	return strings.Join(segments, "/")
+}
+
+// SplitAttributePath returns the attribute names of an attribute path with
+// their quotes removed. It is the inverse of JoinAttributePath and returns a
+// *PathError for malformed paths.
+func SplitAttributePath(path string) ([]string, error) {
+	return splitAttributePath(path, &selectorOptions{})
+}
@@ -0,0 +1,214 @@
+package etsiparser
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const quotedMetadataInput = `
+	{"id":"123", "metadata":{"site/rack":"r12", "zone,region":"eu", "it's":"quoted", "site":{"rack":"r7"}}}
+	`
+
+func TestQuoteAttribute(t *testing.T) {
+	names := map[string]string{
+		"vnfdId":      "vnfdId",
+		"site/rack":   "'site/rack'",
+		"zone,region": "'zone,region'",
+		"it's":        "'it''s'",
+		"f(x)":        "'f(x)'",
+		"[0":          "'[0'",
+		"*":           "'*'",
+		"":            "''",
+	}
+	for name, expectedSegment := range names {
+		assert.Equal(t, expectedSegment, QuoteAttribute(name), name)
+	}
+}
+
+func TestJoinAndSplitAttributePath(t *testing.T) {
+	paths := [][]string{
+		{"metadata", "site/rack"},
+		{"metadata", "zone,region"},
+		{"metadata", "it's", "''"},
+		{"extensions", ""},
+	}
+	for _, names := range paths {
+		path := JoinAttributePath(names...)
+		splitNames, err := SplitAttributePath(path)
+
+		assert.Nil(t, err, path)
+		assert.Equal(t, names, splitNames, path)
+	}
+	assert.Equal(t, "metadata/'site/rack'", JoinAttributePath("metadata", "site/rack"))
+}
+
+func TestSplitAttributePathQuoteErrors(t *testing.T) {
+	paths := map[string]*PathError{
+		"metadata/'site/rack":  {"metadata/'site/rack", 1, "unterminated quote"},
+		"metadata/'site'/rack": nil,
+		"metadata/'site'rack":  {"metadata/'site'rack", 1, "unexpected characters after quote"},
+	}
+	for path, expectedErr := range paths {
+		_, err := SplitAttributePath(path)
+
+		if expectedErr == nil {
+			assert.Nil(t, err, path)
+		} else {
+			assert.Equal(t, expectedErr, err, path)
+		}
+	}
+}
+
+func TestSelectQuotedAttributes(t *testing.T) {
+	attributes := []string{"metadata/'site/rack'", "metadata/'it''s'"}
+
+	modifiedPayload := SelectFields(attributes, unmarshalPayload(t, quotedMetadataInput))
+
+	assert.JSONEq(t, `{"metadata":{"site/rack":"r12", "it's":"quoted"}}`, marshalPayload(modifiedPayload))
+}
+
+func TestExcludeQuotedAttributes(t *testing.T) {
+	attributes := []string{"metadata/'site/rack'", "metadata/'zone,region'"}
+
+	modifiedPayload := ExcludeFields(attributes, unmarshalPayload(t, quotedMetadataInput))
+
+	assert.JSONEq(t, `{"id":"123", "metadata":{"it's":"quoted", "site":{"rack":"r7"}}}`, marshalPayload(modifiedPayload))
+}
+
+func TestParseSelectorQuotedAttributes(t *testing.T) {
+	query, _ := url.ParseQuery("fields=" + url.QueryEscape("id,metadata/'zone,region',metadata/site/rack"))
+	s, err := ParseSelector(query)
+	assert.Nil(t, err)
+
+	assert.JSONEq(t, `{"id":"123", "metadata":{"zone,region":"eu", "site":{"rack":"r7"}}}`, marshalPayload(s.Apply(unmarshalPayload(t, quotedMetadataInput))))
+}
+
+func TestFilterQuotedAttributes(t *testing.T) {
+	f, err := ParseFilter("(eq,metadata/'site/rack',r12);(eq,metadata/'f(x),y',eu)")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"metadata", "site/rack"}, f.Expressions[0].Attribute)
+	assert.Equal(t, []string{"metadata", "f(x),y"}, f.Expressions[1].Attribute)
+	assert.Equal(t, []string{"eu"}, f.Expressions[1].Values)
+	assert.True(t, f.Expressions[0].Match(unmarshalPayload(t, quotedMetadataInput)))
+
+	_, err = ParseFilter("(eq,metadata/'site/rack,r12)")
+
+	assert.NotNil(t, err)
+}
+
+func TestParseFilterQuotedValues(t *testing.T) {
+	f, err := ParseFilter("(eq,vnfInstanceName,'a,b','it''s','f(x);y',plain)")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a,b", "it's", "f(x);y", "plain"}, f.Expressions[0].Values)
+}
+
+func TestParseFilterQuotedValueErrors(t *testing.T) {
+	filters := map[string]int{
+		"(eq,vnfInstanceName,it's)":      20,
+		"(eq,vnfInstanceName,'a'b)":      20,
+		"(eq,vnfInstanceName,web,'a'b)":  24,
+		"(eq,vnfInstanceName,'unclosed)": 0,
+	}
+	for filter, offset := range filters {
+		_, err := ParseFilter(filter)
+
+		syntaxError, ok := err.(*FilterSyntaxError)
+		if assert.True(t, ok, filter) {
+			assert.Equal(t, offset, syntaxError.Offset, filter)
+		}
+	}
+}
+
+func TestQuoteFilterValue(t *testing.T) {
+	values := []string{"web", "a,b", "it's", "f(x);y", "site/rack", ""}
+	for _, value := range values {
+		f, err := ParseFilter("(eq,vnfInstanceName," + QuoteFilterValue(value) + ")")
+
+		assert.Nil(t, err, value)
+		assert.Equal(t, []string{value}, f.Expressions[0].Values, value)
+	}
+	assert.Equal(t, "site/rack", QuoteFilterValue("site/rack"))
+}
+
+func TestParseFilterPercentEncoded(t *testing.T) {
+	filter := "(eq,vnfInstanceName,'a,b','it''s');(neq,id,456)"
+	query := url.Values{FilterParameter: {filter}}.Encode()
+
+	decoded, err := ParseFilter(filter)
+	assert.Nil(t, err)
+	encoded, err := ParseFilter(query[len(FilterParameter)+1:])
+	assert.Nil(t, err)
+	fromQuery, err := ParseFilterQuery("fields=id&" + query)
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"a,b", "it's"}, decoded.Expressions[0].Values)
+	assert.Equal(t, decoded, encoded)
+	assert.Equal(t, &decoded, fromQuery)
+}
+
+func TestParseFilterQuery(t *testing.T) {
+	f, err := ParseFilterQuery("filter=(eq,vnfdId,abc);(eq,vnfInstanceName,'a%2Cb')&filter=(neq,id,456)")
+
+	assert.Nil(t, err)
+	assert.Equal(t, &Filter{Expressions: []Expression{
+		{Operator: OperatorEqual, Attribute: []string{"vnfdId"}, Values: []string{"abc"}},
+		{Operator: OperatorEqual, Attribute: []string{"vnfInstanceName"}, Values: []string{"a,b"}},
+		{Operator: OperatorNotEqual, Attribute: []string{"id"}, Values: []string{"456"}},
+	}}, f)
+
+	f, err = ParseFilterQuery("fields=id")
+
+	assert.Nil(t, err)
+	assert.Nil(t, f)
+
+	_, err = ParseFilterQuery("filter=%zz")
+
+	assert.IsType(t, &FilterSyntaxError{}, err)
+}
+
+func TestParseFilterBracketValues(t *testing.T) {
+	f, err := ParseFilter("(eq,x,[a);(eq,y,[a,b])")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"[a"}, f.Expressions[0].Values)
+	assert.Equal(t, []string{"[a", "b]"}, f.Expressions[1].Values)
+}
+
+func TestSplitBracketsOnlyWithElementSegments(t *testing.T) {
+	assert.Equal(t, []string{"a/[(eq", "b/c", "d)]", "e"}, parseAttributeList([]string{"a/[(eq,b/c,d)],e"}, &selectorOptions{}))
+	assert.Equal(t, []string{"a/[(eq,b/c,d)]", "e"}, parseAttributeList([]string{"a/[(eq,b/c,d)],e"}, &selectorOptions{arrayPredicates: true}))
+
+	segments, err := SplitAttributePath("a/[b/c]")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "[b", "c]"}, segments)
+
+	segments, err = splitAttributePath("a/[b/c]", &selectorOptions{arrayIndexes: true})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "[b/c]"}, segments)
+}
+
+func TestSelectQuotedSpecialSegments(t *testing.T) {
+	input := `{"metadata":{"*":"star", "[0]":"index", "owner":"alice"}, "parts":[{"*":1, "id":2}]}`
+
+	assert.Equal(t, "metadata/'*'", JoinAttributePath("metadata", "*"))
+	assert.Equal(t, "metadata/'[0]'", JoinAttributePath("metadata", "[0]"))
+
+	modifiedPayload := SelectFields([]string{JoinAttributePath("metadata", "*")}, unmarshalPayload(t, input))
+	assert.JSONEq(t, `{"metadata":{"*":"star"}}`, marshalPayload(modifiedPayload))
+
+	modifiedPayload = SelectFields([]string{"metadata/'[0]'", "parts/'*'"}, unmarshalPayload(t, input), ArrayIndexes())
+	assert.JSONEq(t, `{"metadata":{"[0]":"index"}, "parts":[{"*":1}]}`, marshalPayload(modifiedPayload))
+
+	modifiedPayload = ExcludeFields([]string{"metadata/'*'", "parts/[0]/'*'"}, unmarshalPayload(t, input), ArrayIndexes())
+	assert.JSONEq(t, `{"metadata":{"[0]":"index", "owner":"alice"}, "parts":[{"id":2}]}`, marshalPayload(modifiedPayload))
+
+	modifiedPayload = ExcludeFieldsInPlace([]string{"metadata/'*'"}, unmarshalPayload(t, input))
+	assert.JSONEq(t, `{"metadata":{"[0]":"index", "owner":"alice"}, "parts":[{"*":1, "id":2}]}`, marshalPayload(modifiedPayload))
+
+	modifiedPayload = SelectFields([]string{"metadata/*"}, unmarshalPayload(t, input))
+	assert.JSONEq(t, `{"metadata":{"*":"star", "[0]":"index", "owner":"alice"}}`, marshalPayload(modifiedPayload))
+}
@@ -0,0 +1,387 @@
+package etsiparser
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// jsonField is a struct field as seen by encoding/json.
+type jsonField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	tagged    bool
+}
+
+// jsonFieldsCache maps a struct type to its []jsonField.
+var jsonFieldsCache sync.Map
+
+// collectJSONFields adds the fields of t and of its embedded structs to
+// fields. Like encoding/json, an embedded struct type is expanded only at
+// the shallowest depth it occurs at, which also ends the recursion of types
+// that embed themselves.
+func collectJSONFields(t reflect.Type, index []int, depth int, fields map[string][]jsonField, depths map[string]int, visited map[reflect.Type]int) {
+	if d, ok := visited[t]; ok && d <= depth {
+		return
+	}
+	visited[t] = depth
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options := tag, ""
+		if comma := strings.IndexByte(tag, ','); comma >= 0 {
+			name, options = tag[:comma], tag[comma+1:]
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			collectJSONFields(fieldType, fieldIndex, depth+1, fields, depths, visited)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		tagged := name != ""
+		if !tagged {
+			name = field.Name
+		}
+		if d, ok := depths[name]; ok && d < depth {
+			continue
+		} else if !ok || d > depth {
+			fields[name] = nil
+			depths[name] = depth
+		}
+		fields[name] = append(fields[name], jsonField{
+			name:      name,
+			index:     fieldIndex,
+			omitEmpty: strings.Contains(","+options+",", ",omitempty,"),
+			tagged:    tagged,
+		})
+	}
+}
+
+// jsonFieldsOf returns the fields encoding/json marshals for the struct type
+// t. Like encoding/json, the shallowest field wins a name conflict and among
+// fields of equal depth a tagged field wins, otherwise the name is dropped.
+func jsonFieldsOf(t reflect.Type) []jsonField {
+	if cached, ok := jsonFieldsCache.Load(t); ok {
+		return cached.([]jsonField)
+	}
+	fields := make(map[string][]jsonField)
+	collectJSONFields(t, nil, 0, fields, make(map[string]int), make(map[reflect.Type]int))
+	var result []jsonField
+	for _, candidates := range fields {
+		if len(candidates) == 1 {
+			result = append(result, candidates[0])
+			continue
+		}
+		var tagged []jsonField
+		for _, candidate := range candidates {
+			if candidate.tagged {
+				tagged = append(tagged, candidate)
+			}
+		}
+		if len(tagged) == 1 {
+			result = append(result, tagged[0])
+		}
+	}
+	cached, _ := jsonFieldsCache.LoadOrStore(t, result)
+	return cached.([]jsonField)
+}
+
+// jsonFieldsByNameCache maps a struct type to its jsonFields by name.
+var jsonFieldsByNameCache sync.Map
+
+func jsonFieldByName(t reflect.Type, name string) (jsonField, bool) {
+	cached, ok := jsonFieldsByNameCache.Load(t)
+	if !ok {
+		fields := make(map[string]jsonField)
+		for _, field := range jsonFieldsOf(t) {
+			fields[field.name] = field
+		}
+		cached, _ = jsonFieldsByNameCache.LoadOrStore(t, fields)
+	}
+	field, ok := cached.(map[string]jsonField)[name]
+	return field, ok
+}
+
+func isEmptyJSONValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+func isJSONMarshaler(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		t.Kind() != reflect.Ptr && (reflect.PtrTo(t).Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType))
+}
+
+// isJSONNull reports whether encoding/json marshals object as null.
+func isJSONNull(object interface{}) bool {
+	if object == nil {
+		return true
+	}
+	v := reflect.ValueOf(object)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return v.IsNil() && !v.Type().Implements(jsonMarshalerType)
+	}
+	return false
+}
+
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// isJSONMapKeyType reports whether encoding/json marshals maps with keys of
+// type t as objects.
+func isJSONMapKeyType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return t.Implements(textMarshalerType)
+}
+
+// jsonMapKey returns the object member name encoding/json uses for the map
+// key k. It reports false if the key cannot be marshaled.
+func jsonMapKey(k reflect.Value) (string, bool) {
+	if k.Kind() == reflect.String {
+		return k.String(), true
+	}
+	if marshaler, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if k.Kind() == reflect.Ptr && k.IsNil() {
+			return "", true
+		}
+		text, err := marshaler.MarshalText()
+		return string(text), err == nil
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), true
+	}
+	return "", false
+}
+
+// mapKeyByJSONName returns the key of the map v that encoding/json marshals
+// as name.
+func mapKeyByJSONName(v reflect.Value, name string) (reflect.Value, bool) {
+	if v.Type().Key().Kind() == reflect.String {
+		key := reflect.ValueOf(name).Convert(v.Type().Key())
+		return key, v.MapIndex(key).IsValid()
+	}
+	iter := v.MapRange()
+	for iter.Next() {
+		if keyName, ok := jsonMapKey(iter.Key()); ok && keyName == name {
+			return iter.Key(), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// genericValue converts one level of a struct, pointer, typed map or typed
+// slice to the map[string]interface{} or []interface{} that json.Unmarshal
+// would produce for its JSON encoding, leaving the nested values as they
+// are. It reports false for values encoding/json does not marshal as an
+// object or array.
+func genericValue(object interface{}) (interface{}, bool) {
+	v := reflect.ValueOf(object)
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if isJSONMarshaler(v.Type()) {
+			return nil, false
+		}
+		if v.IsNil() {
+			return nil, true
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || isJSONMarshaler(v.Type()) {
+		return nil, false
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		resultMap := make(map[string]interface{})
+		for _, field := range jsonFieldsOf(v.Type()) {
+			value, ok := fieldByIndex(v, field.index)
+			if !ok || !value.CanInterface() || field.omitEmpty && isEmptyJSONValue(value) {
+				continue
+			}
+			resultMap[field.name] = value.Interface()
+		}
+		return resultMap, true
+	case reflect.Map:
+		if !isJSONMapKeyType(v.Type().Key()) {
+			return nil, false
+		}
+		if v.IsNil() {
+			return nil, true
+		}
+		resultMap := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			name, ok := jsonMapKey(iter.Key())
+			if !ok {
+				return nil, false
+			}
+			resultMap[name] = iter.Value().Interface()
+		}
+		return resultMap, true
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return nil, false
+		}
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, true
+		}
+		resultList := make([]interface{}, v.Len())
+		for i := range resultList {
+			resultList[i] = v.Index(i).Interface()
+		}
+		return resultList, true
+	}
+	return nil, false
+}
+
+// excludeFieldsInPlaceValue removes the attributes from the typed maps
+// reachable from v. Struct fields are descended into but never removed.
+func excludeFieldsInPlaceValue(attributesMap map[string]interface{}, v reflect.Value) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		if !isJSONMapKeyType(v.Type().Key()) {
+			return
+		}
+		var keys []reflect.Value
+		if _, wildcard := attributesMap[wildcardKey]; wildcard {
+			keys = v.MapKeys()
+		} else {
+			for field := range attributesMap {
+				if key, ok := mapKeyByJSONName(v, field); ok {
+					keys = append(keys, key)
+				}
+			}
+		}
+		for _, key := range keys {
+			value := v.MapIndex(key)
+			name, ok := jsonMapKey(key)
+			if !value.IsValid() || !ok {
+				continue
+			}
+			newAttributesMap, _ := fieldAttributes(attributesMap, name)
+			if len(newAttributesMap) == 0 {
+				v.SetMapIndex(key, reflect.Value{})
+				continue
+			}
+			excludeFieldsInPlaceRecursively(newAttributesMap, value.Interface())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if item := v.Index(i); item.CanInterface() {
+				excludeFieldsInPlaceRecursively(attributesMap, item.Interface())
+			}
+		}
+	case reflect.Struct:
+		if isJSONMarshaler(v.Type()) {
+			return
+		}
+		for _, field := range jsonFieldsOf(v.Type()) {
+			newAttributesMap, ok := fieldAttributes(attributesMap, field.name)
+			if !ok || len(newAttributesMap) == 0 {
+				continue
+			}
+			if value, ok := fieldByIndex(v, field.index); ok && value.CanInterface() {
+				excludeFieldsInPlaceRecursively(newAttributesMap, value.Interface())
+			}
+		}
+	}
+}
+
+// collectAttributeValuesOf is collectAttributeValues for structs, pointers,
+// typed maps and typed slices. It looks the attributes up by their JSON
+// names instead of converting the values with genericValue.
+func collectAttributeValuesOf(path []string, v reflect.Value) []interface{} {
+	for (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !isJSONMarshaler(v.Type()) {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.CanInterface() {
+		return nil
+	}
+	marshaler := isJSONMarshaler(v.Type())
+	switch {
+	case !marshaler && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() != reflect.Uint8:
+		var values []interface{}
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, collectAttributeValues(path, v.Index(i).Interface())...)
+		}
+		return values
+	case len(path) == 0:
+		return []interface{}{v.Interface()}
+	case marshaler:
+		return nil
+	case v.Kind() == reflect.Map && isJSONMapKeyType(v.Type().Key()):
+		key, ok := mapKeyByJSONName(v, path[0])
+		if !ok {
+			return nil
+		}
+		return collectAttributeValues(path[1:], v.MapIndex(key).Interface())
+	case v.Kind() == reflect.Struct:
+		field, ok := jsonFieldByName(v.Type(), path[0])
+		if !ok {
+			return nil
+		}
+		value, ok := fieldByIndex(v, field.index)
+		if !ok || !value.CanInterface() || field.omitEmpty && isEmptyJSONValue(value) {
+			return nil
+		}
+		return collectAttributeValues(path[1:], value.Interface())
+	}
+	return nil
+}
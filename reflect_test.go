@@ -0,0 +1,298 @@
+package etsiparser
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testLink struct {
+	Href string `json:"href"`
+}
+
+type testVnfcResourceInfo struct {
+	ID    string `json:"id"`
+	VduID string `json:"vduId"`
+}
+
+type testInstantiatedVnfInfo struct {
+	VnfState         string                 `json:"vnfState"`
+	FlavourID        string                 `json:"flavourId"`
+	VnfcResourceInfo []testVnfcResourceInfo `json:"vnfcResourceInfo,omitempty"`
+}
+
+type testVnfInstance struct {
+	ID                  string                   `json:"id"`
+	VnfdID              string                   `json:"vnfdId"`
+	InstantiationState  string                   `json:"instantiationState"`
+	InstantiatedVnfInfo *testInstantiatedVnfInfo `json:"instantiatedVnfInfo,omitempty"`
+	Metadata            map[string]string        `json:"metadata,omitempty"`
+	Links               struct {
+		Self testLink `json:"self"`
+	} `json:"_links"`
+	Secret   string `json:"-"`
+	internal string
+}
+
+func testVnfInstances() []testVnfInstance {
+	instances := []testVnfInstance{
+		{
+			ID:                 "123",
+			VnfdID:             "abc",
+			InstantiationState: "INSTANTIATED",
+			InstantiatedVnfInfo: &testInstantiatedVnfInfo{
+				VnfState:  "STARTED",
+				FlavourID: "small",
+				VnfcResourceInfo: []testVnfcResourceInfo{
+					{ID: "vnfc1", VduID: "VDU1"},
+					{ID: "vnfc2", VduID: "VDU2"},
+				},
+			},
+			Metadata: map[string]string{"owner": "alice"},
+			Secret:   "s3cr3t",
+			internal: "internal",
+		},
+		{
+			ID:                 "456",
+			VnfdID:             "def",
+			InstantiationState: "NOT_INSTANTIATED",
+			Metadata:           map[string]string{"owner": "bob"},
+		},
+	}
+	instances[0].Links.Self.Href = "/vnf_instances/123"
+	instances[1].Links.Self.Href = "/vnf_instances/456"
+	return instances
+}
+
+func marshalPayload(payload interface{}) string {
+	res, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(res))
+	return string(res)
+}
+
+func TestSelectStructs(t *testing.T) {
+	attributes := []string{"id", "instantiatedVnfInfo/vnfcResourceInfo/vduId", "metadata", "_links/self/href"}
+	expectedOutput := `
+	[
+	{"id":"123", "instantiatedVnfInfo":{"vnfcResourceInfo":[{"vduId":"VDU1"}, {"vduId":"VDU2"}]},
+	 "metadata":{"owner":"alice"}, "_links":{"self":{"href":"/vnf_instances/123"}}},
+	{"id":"456", "metadata":{"owner":"bob"}, "_links":{"self":{"href":"/vnf_instances/456"}}}
+	]
+	`
+	modifiedPayload := SelectFields(attributes, testVnfInstances())
+
+	assert.JSONEq(t, expectedOutput, marshalPayload(modifiedPayload))
+}
+
+func TestSelectStructPointers(t *testing.T) {
+	instances := testVnfInstances()
+	attributes := []string{"id", "instantiatedVnfInfo/vnfState"}
+
+	assert.JSONEq(t, `{"id":"123", "instantiatedVnfInfo":{"vnfState":"STARTED"}}`, marshalPayload(SelectFields(attributes, &instances[0])))
+	assert.JSONEq(t, `[{"id":"123", "instantiatedVnfInfo":{"vnfState":"STARTED"}}, {"id":"456"}]`, marshalPayload(SelectFields(attributes, []*testVnfInstance{&instances[0], &instances[1], nil})))
+	assert.JSONEq(t, `null`, marshalPayload(SelectFields(attributes, (*testVnfInstance)(nil))))
+}
+
+func TestSelectStructIgnoredFields(t *testing.T) {
+	attributes := []string{"Secret", "-", "internal", "vnfdId"}
+	expectedOutput := `
+	[
+	{"vnfdId":"abc"},
+	{"vnfdId":"def"}
+	]
+	`
+	modifiedPayload := SelectFields(attributes, testVnfInstances())
+
+	assert.JSONEq(t, expectedOutput, marshalPayload(modifiedPayload))
+}
+
+func TestExcludeStructs(t *testing.T) {
+	instances := testVnfInstances()
+	attributes := []string{"instantiatedVnfInfo/vnfcResourceInfo", "metadata/owner", "_links"}
+	expectedOutput := `
+	[
+	{"id":"123", "vnfdId":"abc", "instantiationState":"INSTANTIATED",
+	 "instantiatedVnfInfo":{"vnfState":"STARTED", "flavourId":"small"}, "metadata":{}},
+	{"id":"456", "vnfdId":"def", "instantiationState":"NOT_INSTANTIATED", "metadata":{}}
+	]
+	`
+	modifiedPayload := ExcludeFields(attributes, instances)
+
+	assert.JSONEq(t, expectedOutput, marshalPayload(modifiedPayload))
+	assert.Equal(t, testVnfInstances(), instances)
+}
+
+func TestResourceSelectorStructs(t *testing.T) {
+	query, _ := url.ParseQuery("exclude_default&fields=instantiatedVnfInfo/vnfState")
+	s, err := ParseResourceSelector("VnfInstance", query)
+	assert.Nil(t, err)
+	expectedOutput := `
+	[
+	{"id":"123", "vnfdId":"abc", "instantiationState":"INSTANTIATED",
+	 "instantiatedVnfInfo":{"vnfState":"STARTED"}, "_links":{"self":{"href":"/vnf_instances/123"}}},
+	{"id":"456", "vnfdId":"def", "instantiationState":"NOT_INSTANTIATED", "_links":{"self":{"href":"/vnf_instances/456"}}}
+	]
+	`
+	assert.JSONEq(t, expectedOutput, marshalPayload(s.Apply(testVnfInstances())))
+}
+
+type testTimestamps struct {
+	StartTime time.Time       `json:"startTime"`
+	Raw       json.RawMessage `json:"raw"`
+	Data      []byte          `json:"data"`
+}
+
+type testEmbedded struct {
+	testTimestamps
+	ID   string `json:"id"`
+	Data string `json:"data"`
+}
+
+func TestSelectStructMarshalers(t *testing.T) {
+	value := testEmbedded{
+		testTimestamps: testTimestamps{
+			StartTime: time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC),
+			Raw:       json.RawMessage(`{"a":1}`),
+			Data:      []byte("hidden"),
+		},
+		ID:   "1",
+		Data: "shadowing",
+	}
+
+	assert.JSONEq(t, `{"startTime":"2021-03-04T05:06:07Z", "raw":{"a":1}, "data":"shadowing"}`, marshalPayload(SelectFields([]string{"startTime", "raw", "data"}, value)))
+	assert.JSONEq(t, `null`, marshalPayload(SelectFields([]string{"startTime/wall", "raw/a"}, value)))
+	assert.JSONEq(t, `{"id":"1"}`, marshalPayload(ExcludeFields([]string{"startTime", "raw", "data"}, value)))
+}
+
+func BenchmarkSelectStructs(b *testing.B) {
+	instances := testVnfInstances()
+	s, err := CompileSelect([]string{"id", "instantiatedVnfInfo/vnfState", "instantiatedVnfInfo/vnfcResourceInfo/vduId", "_links/self"})
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Apply(instances)
+	}
+}
+
+func TestSelectStructNullFieldsPreserved(t *testing.T) {
+	instances := testVnfInstances()
+	instances[0].InstantiatedVnfInfo.VnfcResourceInfo = nil
+	attributes := []string{"instantiatedVnfInfo/vnfcResourceInfo", "instantiatedVnfInfo/vnfState"}
+	value := struct {
+		Name *string                  `json:"vnfInstanceName"`
+		Info *testInstantiatedVnfInfo `json:"instantiatedVnfInfo"`
+	}{Info: instances[0].InstantiatedVnfInfo}
+
+	assert.JSONEq(t, `{"instantiatedVnfInfo":{"vnfState":"STARTED"}}`, marshalPayload(SelectFields(append(attributes, "vnfInstanceName"), value)))
+	assert.JSONEq(t, `{"vnfInstanceName":null, "instantiatedVnfInfo":{"vnfState":"STARTED"}}`, marshalPayload(SelectFields(append(attributes, "vnfInstanceName"), value, PreserveNull())))
+}
+
+func TestFilterStructs(t *testing.T) {
+	instances := testVnfInstances()
+	instances = append(instances, testVnfInstance{ID: "789", InstantiatedVnfInfo: &testInstantiatedVnfInfo{VnfState: "STOPPED"}})
+	filters := map[string][]string{
+		"(eq,instantiatedVnfInfo/vnfState,STARTED)":            {"123"},
+		"(neq,instantiatedVnfInfo/vnfState,STARTED)":           {"456", "789"},
+		"(eq,instantiatedVnfInfo/vnfcResourceInfo/vduId,VDU2)": {"123"},
+		"(eq,metadata/owner,bob)":                              {"456"},
+		"(cont,_links/self/href,/vnf_instances/)":              {"123", "456"},
+		"(eq,Secret,s3cr3t)":                                   nil,
+		"(eq,internal,internal)":                               nil,
+		"(eq,instantiatedVnfInfo/vnfcResourceInfo,VDU2)":       nil,
+	}
+	for filter, expectedIDs := range filters {
+		f, err := ParseFilter(filter)
+		assert.Nil(t, err, filter)
+
+		var ids []string
+		for _, instance := range instances {
+			if f.Match(instance) {
+				ids = append(ids, instance.ID)
+			}
+		}
+		assert.Equal(t, expectedIDs, ids, filter)
+
+		ids = nil
+		for i := range instances {
+			if f.Match(&instances[i]) {
+				ids = append(ids, instances[i].ID)
+			}
+		}
+		assert.Equal(t, expectedIDs, ids, filter)
+	}
+}
+
+func TestFilterStructOmitEmpty(t *testing.T) {
+	f, err := ParseFilter("(eq,instantiatedVnfInfo/vnfcResourceInfo/vduId,VDU1)")
+	assert.Nil(t, err)
+
+	assert.True(t, f.Match(map[string]interface{}{"instantiatedVnfInfo": testVnfInstances()[0].InstantiatedVnfInfo}))
+	assert.False(t, f.Match(map[string]*testInstantiatedVnfInfo{"instantiatedVnfInfo": nil}))
+	assert.False(t, f.Match(testVnfInstance{}))
+}
+
+func BenchmarkFilterStructs(b *testing.B) {
+	instances := testVnfInstances()
+	f, err := ParseFilter("(eq,instantiatedVnfInfo/vnfcResourceInfo/vduId,VDU2);(neq,instantiationState,NOT_INSTANTIATED)")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, instance := range instances {
+			f.Match(&instance)
+		}
+	}
+}
+
+type testNode struct {
+	*testNode
+	Name string `json:"name"`
+}
+
+func TestSelectRecursiveEmbeddedStruct(t *testing.T) {
+	value := testNode{testNode: &testNode{Name: "b"}, Name: "a"}
+
+	assert.JSONEq(t, `{"name":"a"}`, marshalPayload(SelectFields([]string{"name"}, value)))
+	assert.JSONEq(t, `{"name":"a"}`, marshalPayload(ExcludeFields([]string{"testNode"}, value)))
+	assert.JSONEq(t, `{"name":"a"}`, marshalPayload(SelectFields([]string{"name"}, testNode{Name: "a"})))
+}
+
+type testKey struct {
+	a, b string
+}
+
+func (k testKey) MarshalText() ([]byte, error) {
+	return []byte(k.a + "." + k.b), nil
+}
+
+func TestSelectNonStringMapKeys(t *testing.T) {
+	payload := map[string]interface{}{
+		"byIdx":  map[int]string{1: "a", 2: "b"},
+		"byUint": map[uint8]string{3: "c"},
+		"byText": map[testKey]string{{"x", "y"}: "d", {"x", "z"}: "e"},
+		"byBool": map[bool]string{true: "f"},
+	}
+
+	assert.JSONEq(t, `{"byIdx":{"1":"a"}}`, marshalPayload(SelectFields([]string{"byIdx/1"}, payload)))
+	assert.JSONEq(t, `{"byUint":{"3":"c"}, "byText":{"x.y":"d"}}`,
+		marshalPayload(SelectFields([]string{"byUint/3", "byText/x.y"}, payload)))
+	assert.Nil(t, SelectFields([]string{"byBool/true"}, payload))
+
+	f, err := ParseFilter("(eq,byIdx/2,b);(eq,byText/x.z,e)")
+	assert.Nil(t, err)
+	assert.True(t, f.Match(payload))
+
+	ExcludeFieldsInPlace([]string{"byIdx/1", "byText/x.y"}, payload)
+	assert.Equal(t, map[int]string{2: "b"}, payload["byIdx"])
+	assert.Equal(t, map[testKey]string{{"x", "z"}: "e"}, payload["byText"])
+}
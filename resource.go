@@ -0,0 +1,86 @@
+package etsiparser
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+)
+
+// ResourceDescriptor declares how attribute selectors apply to a resource
+// type, e.g. "VnfInstance".
+type ResourceDescriptor struct {
+	Name string
+	// DefaultExcluded lists the complex attributes that exclude_default
+	// drops from the representation.
+	DefaultExcluded []string
+	// Mandatory lists the attributes the API specification requires in
+	// every representation of the resource. Selectors never drop them.
+	Mandatory []string
+}
+
+// UnknownResourceError is returned for resource types that have not been
+// registered.
+type UnknownResourceError struct {
+	Name string
+}
+
+func (e *UnknownResourceError) Error() string {
+	return fmt.Sprintf("unknown resource type %q", e.Name)
+}
+
+// Registry holds resource descriptors by name. It is safe for concurrent use.
+type Registry struct {
+	mu        sync.RWMutex
+	resources map[string]ResourceDescriptor
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{resources: make(map[string]ResourceDescriptor)}
+}
+
+// DefaultRegistry is the registry used by RegisterResource and
+// ParseResourceSelector.
+var DefaultRegistry = NewRegistry()
+
+// Register adds the descriptor to the registry, replacing any descriptor
+// registered under the same name.
+func (r *Registry) Register(descriptor ResourceDescriptor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.resources[descriptor.Name] = descriptor
+}
+
+// Lookup returns the descriptor registered under name.
+func (r *Registry) Lookup(name string) (ResourceDescriptor, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	descriptor, ok := r.resources[name]
+	return descriptor, ok
+}
+
+// ParseSelector parses the attribute selector parameters of a query for the
+// named resource type, expanding exclude_default to its default-excluded
+// attributes and keeping its mandatory attributes.
+func (r *Registry) ParseSelector(name string, query url.Values, options ...SelectorOption) (*Selector, error) {
+	descriptor, ok := r.Lookup(name)
+	if !ok {
+		return nil, &UnknownResourceError{name}
+	}
+	s, err := ParseSelector(query, options...)
+	if err != nil {
+		return nil, err
+	}
+	return s.WithDefaultExcluded(descriptor.DefaultExcluded).WithMandatory(descriptor.Mandatory), nil
+}
+
+// RegisterResource adds the descriptor to DefaultRegistry.
+func RegisterResource(descriptor ResourceDescriptor) {
+	DefaultRegistry.Register(descriptor)
+}
+
+// ParseResourceSelector parses the attribute selector parameters of a query
+// for a resource type registered in DefaultRegistry.
+func ParseResourceSelector(name string, query url.Values, options ...SelectorOption) (*Selector, error) {
+	return DefaultRegistry.ParseSelector(name, query, options...)
+}
@@ -0,0 +1,126 @@
+package etsiparser
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryExcludeDefault(t *testing.T) {
+	r := NewRegistry()
+	r.Register(ResourceDescriptor{
+		Name:            "VnfInstance",
+		DefaultExcluded: []string{"instantiatedVnfInfo", "metadata"},
+	})
+	query, _ := url.ParseQuery("exclude_default")
+	s, err := r.ParseSelector("VnfInstance", query)
+	assert.Nil(t, err)
+	expectedOutput := `
+	[
+	{"id":"123", "vnfdId":"abc", "instantiationState":"INSTANTIATED", "_links":{"self":{"href":"/vnf_instances/123"}}},
+	{"id":"456", "vnfdId":"def", "instantiationState":"NOT_INSTANTIATED", "_links":{"self":{"href":"/vnf_instances/456"}}}
+	]
+	`
+	assert.JSONEq(t, expectedOutput, applySelector(t, s, vnfInstancesInput))
+}
+
+func TestRegistryExcludeDefaultAndFields(t *testing.T) {
+	r := NewRegistry()
+	r.Register(ResourceDescriptor{
+		Name:            "VnfInstance",
+		DefaultExcluded: []string{"instantiatedVnfInfo", "metadata"},
+	})
+	query, _ := url.ParseQuery("exclude_default&fields=instantiatedVnfInfo/vnfState")
+	s, err := r.ParseSelector("VnfInstance", query)
+	assert.Nil(t, err)
+	expectedOutput := `
+	[
+	{"id":"123", "vnfdId":"abc", "instantiationState":"INSTANTIATED",
+	 "instantiatedVnfInfo":{"vnfState":"STARTED"}, "_links":{"self":{"href":"/vnf_instances/123"}}},
+	{"id":"456", "vnfdId":"def", "instantiationState":"NOT_INSTANTIATED", "_links":{"self":{"href":"/vnf_instances/456"}}}
+	]
+	`
+	assert.JSONEq(t, expectedOutput, applySelector(t, s, vnfInstancesInput))
+}
+
+func TestRegistryUnknownResource(t *testing.T) {
+	s, err := NewRegistry().ParseSelector("VnfInstance", url.Values{})
+
+	assert.Nil(t, s)
+	assert.Equal(t, &UnknownResourceError{"VnfInstance"}, err)
+}
+
+func TestRegistryConflictingParameters(t *testing.T) {
+	r := NewRegistry()
+	r.Register(ResourceDescriptor{Name: "VnfInstance"})
+	query, _ := url.ParseQuery("all_fields&exclude_default")
+	s, err := r.ParseSelector("VnfInstance", query)
+
+	assert.Nil(t, s)
+	assert.Equal(t, &SelectorConflictError{AllFieldsParameter, ExcludeDefaultParameter}, err)
+}
+
+func TestBuiltinDescriptorsRegistered(t *testing.T) {
+	for _, name := range []string{
+		"VnfInstance", "VnfLcmOpOcc", "VnfPkgInfo", "NsInstance",
+		"NsLcmOpOcc", "PmJob", "Alarm", "Subscription",
+	} {
+		descriptor, ok := DefaultRegistry.Lookup(name)
+
+		assert.True(t, ok, name)
+		assert.Equal(t, name, descriptor.Name)
+		assert.Contains(t, descriptor.Mandatory, "id", name)
+		assert.Contains(t, descriptor.Mandatory, "_links", name)
+	}
+}
+
+func TestBuiltinVnfInstanceExcludeDefault(t *testing.T) {
+	s, err := ParseResourceSelector("VnfInstance", url.Values{})
+	assert.Nil(t, err)
+	expectedOutput := `
+	[
+	{"id":"123", "vnfdId":"abc", "instantiationState":"INSTANTIATED", "_links":{"self":{"href":"/vnf_instances/123"}}},
+	{"id":"456", "vnfdId":"def", "instantiationState":"NOT_INSTANTIATED", "_links":{"self":{"href":"/vnf_instances/456"}}}
+	]
+	`
+	assert.JSONEq(t, expectedOutput, applySelector(t, s, vnfInstancesInput))
+}
+
+func TestBuiltinVnfInstanceMandatory(t *testing.T) {
+	query, _ := url.ParseQuery("fields=metadata")
+	s, err := ParseResourceSelector("VnfInstance", query)
+	assert.Nil(t, err)
+	expectedOutput := `
+	[
+	{"id":"123", "vnfdId":"abc", "instantiationState":"INSTANTIATED",
+	 "metadata":{"owner":"alice"}, "_links":{"self":{"href":"/vnf_instances/123"}}},
+	{"id":"456", "vnfdId":"def", "instantiationState":"NOT_INSTANTIATED",
+	 "metadata":{"owner":"bob"}, "_links":{"self":{"href":"/vnf_instances/456"}}}
+	]
+	`
+	assert.JSONEq(t, expectedOutput, applySelector(t, s, vnfInstancesInput))
+}
+
+func TestResourceSelectorFieldsWithinMandatory(t *testing.T) {
+	input := `{"id":"123", "vnfdId":"abc", "metadata":{"owner":"alice"},
+	 "_links":{"self":{"href":"/vnf_instances/123"}, "instantiate":{"href":"/vnf_instances/123/instantiate"}}}`
+	expectedOutput := `{"id":"123", "vnfdId":"abc",
+	 "_links":{"self":{"href":"/vnf_instances/123"}, "instantiate":{"href":"/vnf_instances/123/instantiate"}}}`
+	for _, rawQuery := range []string{"fields=_links/self", "exclude_default&fields=_links/self"} {
+		query, _ := url.ParseQuery(rawQuery)
+		s, err := ParseResourceSelector("VnfInstance", query)
+		assert.Nil(t, err)
+
+		assert.JSONEq(t, expectedOutput, marshalPayload(s.Apply(unmarshalPayload(t, input))), rawQuery)
+	}
+}
+
+func TestCreateAttributesMapWholeAttributeWins(t *testing.T) {
+	for _, attributes := range [][]string{{"_links/self/href", "_links"}, {"_links", "_links/self/href"}} {
+		attributesMap, err := createAttributesMap(append(attributes, "id"), &selectorOptions{})
+
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]interface{}{"_links": map[string]interface{}{}, "id": map[string]interface{}{}}, attributesMap)
+	}
+}
@@ -0,0 +1,247 @@
+package etsiparser
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// URI query parameters for attribute selectors as defined in ETSI GS NFV-SOL
+// 013 clause 5.3.
+const (
+	AllFieldsParameter      = "all_fields"
+	FieldsParameter         = "fields"
+	ExcludeFieldsParameter  = "exclude_fields"
+	ExcludeDefaultParameter = "exclude_default"
+)
+
+// Selector applies the attribute selector parameters of a request to a
+// resource representation. Its attribute paths are compiled once, a Selector
+// is immutable and safe for concurrent use.
+type Selector struct {
+	allFields       bool
+	fields          []string
+	excludeFields   []string
+	excludeDefault  bool
+	defaultExcluded []string
+	mandatory       []string
+	options         selectorOptions
+
+	selectMap  map[string]interface{}
+	excludeMap map[string]interface{}
+	includeMap map[string]interface{}
+}
+
+type selectorOptions struct {
+	preserveNull    bool
+	preserveEmpty   bool
+	arrayIndexes    bool
+	arrayPredicates bool
+}
+
+// SelectorOption configures how a Selector builds the representation.
+type SelectorOption func(*selectorOptions)
+
+// PreserveNull keeps selected attributes whose value is null instead of
+// dropping them, so that "present but null" can be told from "absent".
+func PreserveNull() SelectorOption {
+	return func(o *selectorOptions) {
+		o.preserveNull = true
+	}
+}
+
+// PreserveEmpty keeps selected arrays and objects that end up empty as []
+// and {} instead of dropping them. An array ends up empty if it was empty or
+// none of its items hold the selected sub-attributes, as such items are
+// dropped rather than kept as {}.
+func PreserveEmpty() SelectorOption {
+	return func(o *selectorOptions) {
+		o.preserveEmpty = true
+	}
+}
+
+// ArrayIndexes enables the non-standard array index segments "[i]" and
+// "[i:j]" in attribute paths, e.g. "vnfcResourceInfo/[0]/computeResource"
+// or "vnfcResourceInfo/[:3]". Without it such segments are plain attribute
+// names.
+func ArrayIndexes() SelectorOption {
+	return func(o *selectorOptions) {
+		o.arrayIndexes = true
+	}
+}
+
+// ArrayPredicates enables the non-standard predicate segments "[filter]" in
+// attribute paths, which address the array elements an attribute-based
+// filter matches, e.g. "vnfcResourceInfo/[(eq,vduId,VDU1)]/computeResource".
+// Without it such segments are plain attribute names.
+func ArrayPredicates() SelectorOption {
+	return func(o *selectorOptions) {
+		o.arrayPredicates = true
+	}
+}
+
+// elementSegments reports whether attribute paths may hold element segments.
+func (o *selectorOptions) elementSegments() bool {
+	return o.arrayIndexes || o.arrayPredicates
+}
+
+func newSelector(options []SelectorOption) *Selector {
+	s := &Selector{}
+	for _, option := range options {
+		option(&s.options)
+	}
+	return s
+}
+
+// CompileSelect returns a selector that keeps only the listed attributes,
+// like SelectFields. It returns a *PathError for malformed attribute paths.
+func CompileSelect(attributesList []string, options ...SelectorOption) (*Selector, error) {
+	s := newSelector(options)
+	s.fields = attributesList
+	if err := s.compile(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// CompileExclude returns a selector that drops the listed attributes, like
+// ExcludeFields. It returns a *PathError for malformed attribute paths.
+func CompileExclude(attributesList []string, options ...SelectorOption) (*Selector, error) {
+	s := newSelector(options)
+	s.excludeFields = attributesList
+	if err := s.compile(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SelectorConflictError reports two attribute selector parameters that
+// SOL013 Table 5.3.2-1 does not allow in the same request.
+type SelectorConflictError struct {
+	Parameter   string
+	Conflicting string
+}
+
+func (e *SelectorConflictError) Error() string {
+	return fmt.Sprintf("parameter %q cannot be combined with %q", e.Parameter, e.Conflicting)
+}
+
+var selectorConflicts = [][2]string{
+	{AllFieldsParameter, FieldsParameter},
+	{AllFieldsParameter, ExcludeFieldsParameter},
+	{AllFieldsParameter, ExcludeDefaultParameter},
+	{ExcludeFieldsParameter, FieldsParameter},
+	{ExcludeFieldsParameter, ExcludeDefaultParameter},
+}
+
+func parseAttributeList(values []string, options *selectorOptions) []string {
+	var attributes []string
+	for _, value := range values {
+		for _, attribute := range splitUnquoted(value, ',', options.elementSegments()) {
+			if attribute != "" {
+				attributes = append(attributes, attribute)
+			}
+		}
+	}
+	return attributes
+}
+
+// ParseSelector reads the all_fields, fields, exclude_fields and
+// exclude_default parameters from a query. all_fields and exclude_fields
+// cannot be combined with any other of them, whereas fields combines with
+// exclude_default by adding the listed attributes back. Without any of the
+// parameters the selector behaves as exclude_default. Malformed attribute
+// paths are reported as *PathError.
+func ParseSelector(query url.Values, options ...SelectorOption) (*Selector, error) {
+	for _, conflict := range selectorConflicts {
+		_, present := query[conflict[0]]
+		_, conflicting := query[conflict[1]]
+		if present && conflicting {
+			return nil, &SelectorConflictError{conflict[0], conflict[1]}
+		}
+	}
+	_, allFields := query[AllFieldsParameter]
+	_, excludeDefault := query[ExcludeDefaultParameter]
+	s := newSelector(options)
+	s.allFields = allFields
+	s.fields = parseAttributeList(query[FieldsParameter], &s.options)
+	s.excludeFields = parseAttributeList(query[ExcludeFieldsParameter], &s.options)
+	s.excludeDefault = excludeDefault
+	if !s.allFields && len(s.fields) == 0 && len(s.excludeFields) == 0 {
+		s.excludeDefault = true
+	}
+	if err := s.compile(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// WithDefaultExcluded returns a copy of the selector that drops the given
+// complex attributes when exclude_default applies. Malformed attribute paths
+// are ignored.
+func (s *Selector) WithDefaultExcluded(attributes []string) *Selector {
+	c := *s
+	c.defaultExcluded = attributes
+	c.compile()
+	return &c
+}
+
+// WithMandatory returns a copy of the selector that keeps the given
+// attributes regardless of the selector parameters. Malformed attribute paths
+// are ignored.
+func (s *Selector) WithMandatory(attributes []string) *Selector {
+	c := *s
+	c.mandatory = attributes
+	c.compile()
+	return &c
+}
+
+func (s *Selector) included() []string {
+	attributes := make([]string, 0, len(s.fields)+len(s.mandatory))
+	attributes = append(attributes, s.fields...)
+	return append(attributes, s.mandatory...)
+}
+
+// compile builds the attribute maps of the selector, skipping malformed
+// paths. It reports the first malformed path of fields or exclude_fields.
+func (s *Selector) compile() error {
+	var err error
+	s.selectMap, s.excludeMap, s.includeMap = nil, nil, nil
+	switch {
+	case s.allFields:
+	case len(s.excludeFields) > 0:
+		s.excludeMap, err = createAttributesMap(s.excludeFields, &s.options)
+		s.includeMap, _ = createAttributesMap(s.mandatory, &s.options)
+	case s.excludeDefault:
+		s.excludeMap, _ = createAttributesMap(s.defaultExcluded, &s.options)
+		_, err = createAttributesMap(s.fields, &s.options)
+		s.includeMap, _ = createAttributesMap(s.included(), &s.options)
+	case len(s.fields) > 0 || len(s.mandatory) > 0:
+		// The selector keeps selecting even if all paths are malformed,
+		// in which case nothing is selected.
+		_, err = createAttributesMap(s.fields, &s.options)
+		s.selectMap, _ = createAttributesMap(s.included(), &s.options)
+	}
+	return err
+}
+
+// changesNothing reports whether Apply returns any data as it is.
+func (s *Selector) changesNothing() bool {
+	return s.selectMap == nil && len(s.excludeMap) == 0
+}
+
+// Apply returns the representation of data selected by the selector.
+// Mandatory attributes are kept by every selector. data is never modified.
+func (s *Selector) Apply(data interface{}) interface{} {
+	switch {
+	case data == nil:
+		return nil
+	case s.selectMap != nil:
+		if len(s.selectMap) == 0 {
+			return nil
+		}
+		return selectFieldsRecursively(s.selectMap, data, &s.options)
+	case len(s.excludeMap) > 0:
+		return excludeFieldsRecursively(s.excludeMap, s.includeMap, data, &s.options)
+	}
+	return data
+}
@@ -0,0 +1,314 @@
+package etsiparser
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const vnfInstancesInput = `
+	[
+	{"id":"123", "vnfdId":"abc", "instantiationState":"INSTANTIATED",
+	 "instantiatedVnfInfo":{"vnfState":"STARTED", "flavourId":"small", "vnfcResourceInfo":[{"id":"vnfc1", "vduId":"VDU1"}, {"id":"vnfc2", "vduId":"VDU2"}]},
+	 "metadata":{"owner":"alice"}, "_links":{"self":{"href":"/vnf_instances/123"}}},
+	{"id":"456", "vnfdId":"def", "instantiationState":"NOT_INSTANTIATED",
+	 "metadata":{"owner":"bob"}, "_links":{"self":{"href":"/vnf_instances/456"}}}
+	]
+	`
+
+func applySelector(t *testing.T, s *Selector, input string) string {
+	var payload interface{}
+	err := json.Unmarshal([]byte(input), &payload)
+	assert.Nil(t, err)
+
+	modifiedPayload := s.Apply(payload)
+
+	res, err := json.MarshalIndent(modifiedPayload, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(res))
+	return string(res)
+}
+
+func TestSelectorAllFields(t *testing.T) {
+	query, _ := url.ParseQuery("all_fields")
+	s, err := ParseSelector(query)
+	assert.Nil(t, err)
+	s = s.WithDefaultExcluded([]string{"instantiatedVnfInfo", "metadata"})
+
+	assert.JSONEq(t, vnfInstancesInput, applySelector(t, s, vnfInstancesInput))
+}
+
+func TestSelectorFields(t *testing.T) {
+	query, _ := url.ParseQuery("fields=id,instantiatedVnfInfo/vnfState")
+	s, err := ParseSelector(query)
+	assert.Nil(t, err)
+	expectedOutput := `
+	[
+	{"id":"123", "instantiatedVnfInfo":{"vnfState":"STARTED"}},
+	{"id":"456"}
+	]
+	`
+	assert.JSONEq(t, expectedOutput, applySelector(t, s, vnfInstancesInput))
+}
+
+func TestSelectorExcludeFields(t *testing.T) {
+	query, _ := url.ParseQuery("exclude_fields=instantiatedVnfInfo/vnfcResourceInfo,metadata,_links")
+	s, err := ParseSelector(query)
+	assert.Nil(t, err)
+	expectedOutput := `
+	[
+	{"id":"123", "vnfdId":"abc", "instantiationState":"INSTANTIATED",
+	 "instantiatedVnfInfo":{"vnfState":"STARTED", "flavourId":"small"}},
+	{"id":"456", "vnfdId":"def", "instantiationState":"NOT_INSTANTIATED"}
+	]
+	`
+	assert.JSONEq(t, expectedOutput, applySelector(t, s, vnfInstancesInput))
+}
+
+func TestSelectorExcludeDefault(t *testing.T) {
+	query, _ := url.ParseQuery("exclude_default")
+	s, err := ParseSelector(query)
+	assert.Nil(t, err)
+	s = s.WithDefaultExcluded([]string{"instantiatedVnfInfo", "metadata"})
+	expectedOutput := `
+	[
+	{"id":"123", "vnfdId":"abc", "instantiationState":"INSTANTIATED", "_links":{"self":{"href":"/vnf_instances/123"}}},
+	{"id":"456", "vnfdId":"def", "instantiationState":"NOT_INSTANTIATED", "_links":{"self":{"href":"/vnf_instances/456"}}}
+	]
+	`
+	assert.JSONEq(t, expectedOutput, applySelector(t, s, vnfInstancesInput))
+}
+
+func TestSelectorWithoutParameters(t *testing.T) {
+	s, err := ParseSelector(url.Values{})
+	assert.Nil(t, err)
+	s = s.WithDefaultExcluded([]string{"instantiatedVnfInfo", "metadata"})
+	expectedOutput := `
+	[
+	{"id":"123", "vnfdId":"abc", "instantiationState":"INSTANTIATED", "_links":{"self":{"href":"/vnf_instances/123"}}},
+	{"id":"456", "vnfdId":"def", "instantiationState":"NOT_INSTANTIATED", "_links":{"self":{"href":"/vnf_instances/456"}}}
+	]
+	`
+	assert.JSONEq(t, expectedOutput, applySelector(t, s, vnfInstancesInput))
+}
+
+func TestSelectorExcludeDefaultAndFields(t *testing.T) {
+	query, _ := url.ParseQuery("exclude_default&fields=instantiatedVnfInfo/vnfcResourceInfo/vduId,metadata")
+	s, err := ParseSelector(query)
+	assert.Nil(t, err)
+	s = s.WithDefaultExcluded([]string{"instantiatedVnfInfo", "metadata", "_links"})
+	expectedOutput := `
+	[
+	{"id":"123", "vnfdId":"abc", "instantiationState":"INSTANTIATED",
+	 "instantiatedVnfInfo":{"vnfcResourceInfo":[{"vduId":"VDU1"}, {"vduId":"VDU2"}]},
+	 "metadata":{"owner":"alice"}},
+	{"id":"456", "vnfdId":"def", "instantiationState":"NOT_INSTANTIATED",
+	 "metadata":{"owner":"bob"}}
+	]
+	`
+	assert.JSONEq(t, expectedOutput, applySelector(t, s, vnfInstancesInput))
+}
+
+func TestSelectorExcludeDefaultDoesNotModifyInput(t *testing.T) {
+	query, _ := url.ParseQuery("exclude_default")
+	s, err := ParseSelector(query)
+	assert.Nil(t, err)
+	s = s.WithDefaultExcluded([]string{"instantiatedVnfInfo/vnfcResourceInfo"})
+	var payload interface{}
+	err = json.Unmarshal([]byte(vnfInstancesInput), &payload)
+	assert.Nil(t, err)
+
+	s.Apply(payload)
+
+	res, err := json.Marshal(payload)
+	assert.Nil(t, err)
+	assert.JSONEq(t, vnfInstancesInput, string(res))
+}
+
+func TestSelectorRepeatedParameters(t *testing.T) {
+	query, _ := url.ParseQuery("fields=id&fields=vnfdId,")
+	s, err := ParseSelector(query)
+	assert.Nil(t, err)
+	expectedOutput := `
+	[
+	{"id":"123", "vnfdId":"abc"},
+	{"id":"456", "vnfdId":"def"}
+	]
+	`
+	assert.JSONEq(t, expectedOutput, applySelector(t, s, vnfInstancesInput))
+}
+
+func TestSelectorConflictingParameters(t *testing.T) {
+	conflicts := map[string]SelectorConflictError{
+		"all_fields&fields=id":                            {AllFieldsParameter, FieldsParameter},
+		"all_fields&exclude_fields=metadata":              {AllFieldsParameter, ExcludeFieldsParameter},
+		"all_fields&exclude_default":                      {AllFieldsParameter, ExcludeDefaultParameter},
+		"exclude_fields=metadata&fields=id":               {ExcludeFieldsParameter, FieldsParameter},
+		"exclude_fields=metadata&exclude_default":         {ExcludeFieldsParameter, ExcludeDefaultParameter},
+		"fields=id&exclude_default&exclude_fields=_links": {ExcludeFieldsParameter, FieldsParameter},
+	}
+	for rawQuery, expectedError := range conflicts {
+		query, _ := url.ParseQuery(rawQuery)
+		s, err := ParseSelector(query)
+
+		assert.Nil(t, s, rawQuery)
+		assert.Equal(t, &expectedError, err, rawQuery)
+	}
+}
+
+func TestSelectorMandatoryWithFields(t *testing.T) {
+	query, _ := url.ParseQuery("fields=vnfdId")
+	s, err := ParseSelector(query)
+	assert.Nil(t, err)
+	s = s.WithMandatory([]string{"id", "_links/self"})
+	expectedOutput := `
+	[
+	{"id":"123", "vnfdId":"abc", "_links":{"self":{"href":"/vnf_instances/123"}}},
+	{"id":"456", "vnfdId":"def", "_links":{"self":{"href":"/vnf_instances/456"}}}
+	]
+	`
+	assert.JSONEq(t, expectedOutput, applySelector(t, s, vnfInstancesInput))
+}
+
+func TestSelectorMandatoryWithExcludeFields(t *testing.T) {
+	query, _ := url.ParseQuery("exclude_fields=id,vnfdId,instantiationState,instantiatedVnfInfo,metadata,_links")
+	s, err := ParseSelector(query)
+	assert.Nil(t, err)
+	s = s.WithMandatory([]string{"id", "_links/self", "instantiatedVnfInfo/vnfState"})
+	expectedOutput := `
+	[
+	{"id":"123", "instantiatedVnfInfo":{"vnfState":"STARTED"}, "_links":{"self":{"href":"/vnf_instances/123"}}},
+	{"id":"456", "_links":{"self":{"href":"/vnf_instances/456"}}}
+	]
+	`
+	assert.JSONEq(t, expectedOutput, applySelector(t, s, vnfInstancesInput))
+}
+
+func TestSelectorMandatoryWithExcludeDefault(t *testing.T) {
+	query, _ := url.ParseQuery("exclude_default")
+	s, err := ParseSelector(query)
+	assert.Nil(t, err)
+	s = s.WithDefaultExcluded([]string{"instantiatedVnfInfo", "metadata"}).WithMandatory([]string{"metadata/owner"})
+	expectedOutput := `
+	[
+	{"id":"123", "vnfdId":"abc", "instantiationState":"INSTANTIATED",
+	 "metadata":{"owner":"alice"}, "_links":{"self":{"href":"/vnf_instances/123"}}},
+	{"id":"456", "vnfdId":"def", "instantiationState":"NOT_INSTANTIATED",
+	 "metadata":{"owner":"bob"}, "_links":{"self":{"href":"/vnf_instances/456"}}}
+	]
+	`
+	assert.JSONEq(t, expectedOutput, applySelector(t, s, vnfInstancesInput))
+}
+
+func TestCompiledSelectorConcurrentApply(t *testing.T) {
+	s, err := CompileExclude([]string{"instantiatedVnfInfo/vnfcResourceInfo/vduId", "metadata"})
+	assert.Nil(t, err)
+	var payload interface{}
+	err = json.Unmarshal([]byte(vnfInstancesInput), &payload)
+	assert.Nil(t, err)
+	expectedOutput := `
+	[
+	{"id":"123", "vnfdId":"abc", "instantiationState":"INSTANTIATED",
+	 "instantiatedVnfInfo":{"vnfState":"STARTED", "flavourId":"small", "vnfcResourceInfo":[{"id":"vnfc1"}, {"id":"vnfc2"}]},
+	 "_links":{"self":{"href":"/vnf_instances/123"}}},
+	{"id":"456", "vnfdId":"def", "instantiationState":"NOT_INSTANTIATED",
+	 "_links":{"self":{"href":"/vnf_instances/456"}}}
+	]
+	`
+
+	var wg sync.WaitGroup
+	results := make([]interface{}, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = s.Apply(payload)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, result := range results {
+		res, err := json.Marshal(result)
+		assert.Nil(t, err)
+		assert.JSONEq(t, expectedOutput, string(res))
+	}
+}
+
+func BenchmarkSelectFields(b *testing.B) {
+	var payload interface{}
+	if err := json.Unmarshal([]byte(vnfInstancesInput), &payload); err != nil {
+		b.Fatal(err)
+	}
+	attributes := []string{"id", "instantiatedVnfInfo/vnfState", "instantiatedVnfInfo/vnfcResourceInfo/vduId", "_links/self"}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		SelectFields(attributes, payload)
+	}
+}
+
+func BenchmarkCompiledSelectorApply(b *testing.B) {
+	var payload interface{}
+	if err := json.Unmarshal([]byte(vnfInstancesInput), &payload); err != nil {
+		b.Fatal(err)
+	}
+	s, err := CompileSelect([]string{"id", "instantiatedVnfInfo/vnfState", "instantiatedVnfInfo/vnfcResourceInfo/vduId", "_links/self"})
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Apply(payload)
+	}
+}
+
+func TestCompileMalformedPaths(t *testing.T) {
+	paths := map[string]PathError{
+		"":     {"", 0, "empty path"},
+		"/a":   {"/a", 0, "empty segment"},
+		"a/":   {"a/", 1, "empty segment"},
+		"a//b": {"a//b", 1, "empty segment"},
+	}
+	for path, expectedError := range paths {
+		s, err := CompileSelect([]string{"id", path})
+
+		assert.Nil(t, s, path)
+		assert.Equal(t, &expectedError, err, path)
+
+		s, err = CompileExclude([]string{path})
+
+		assert.Nil(t, s, path)
+		assert.Equal(t, &expectedError, err, path)
+	}
+}
+
+func TestSelectorMalformedPaths(t *testing.T) {
+	for _, rawQuery := range []string{
+		"fields=id,instantiatedVnfInfo//vnfState",
+		"exclude_fields=metadata/",
+		"exclude_default&fields=/metadata",
+	} {
+		query, _ := url.ParseQuery(rawQuery)
+		s, err := ParseSelector(query)
+
+		assert.Nil(t, s, rawQuery)
+		_, ok := err.(*PathError)
+		assert.True(t, ok, rawQuery)
+	}
+}
+
+func TestSelectorPreserveNullWithExcludeDefault(t *testing.T) {
+	input := `{"id":"123", "vnfInstanceName":null, "instantiatedVnfInfo":{"vnfState":null, "flavourId":"small"}}`
+	query, _ := url.ParseQuery("exclude_default&fields=instantiatedVnfInfo/vnfState")
+	s, err := ParseSelector(query, PreserveNull())
+	assert.Nil(t, err)
+	s = s.WithDefaultExcluded([]string{"instantiatedVnfInfo"})
+	expectedOutput := `{"id":"123", "vnfInstanceName":null, "instantiatedVnfInfo":{"vnfState":null}}`
+
+	assert.JSONEq(t, expectedOutput, applySelector(t, s, input))
+}